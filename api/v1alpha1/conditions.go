@@ -47,10 +47,60 @@ const ReasonPolicyError string = "PolicyError"
 // error, and found to be compliant. The status of the condition should be True.
 const ReasonPolicyCompliant string = "PolicyCompliant"
 
+// ReasonComplianceUnknown should be used when the policy was evaluated without
+// error, but its compliance could not be determined. The status of the
+// condition should be Unknown.
+const ReasonComplianceUnknown string = "ComplianceUnknown"
+
+// ReadyConditionType is the condition type that indicates whether the
+// controller is able to evaluate the policy. When it is False, the compliance
+// of the policy could not be determined, which is different from the policy
+// being evaluated and found to be not compliant.
+const ReadyConditionType string = "Ready"
+
+// EvaluatingConditionType is the condition type that indicates whether an
+// evaluation of the policy is in progress. Controllers with evaluations that
+// take a while can set it, so that users know a new result is coming.
+const EvaluatingConditionType string = "Evaluating"
+
+// DegradedConditionType is the condition type that indicates whether the
+// controller failed to do something the policy asked for, like enforcing it.
+// The policy may still have been evaluated successfully.
+const DegradedConditionType string = "Degraded"
+
+// ReasonEvaluated should be used on the Ready condition when the policy was
+// evaluated, regardless of whether it was found to be compliant. The status of
+// the condition should be True. When the policy could not be evaluated, the
+// Ready condition should be False with the PolicyError reason.
+const ReasonEvaluated string = "Evaluated"
+
+// ReasonEvaluationInProgress should be used on the Evaluating condition while
+// the policy is being evaluated. The status of the condition should be True.
+const ReasonEvaluationInProgress string = "EvaluationInProgress"
+
+// ReasonEvaluationComplete should be used on the Evaluating condition when the
+// evaluation has finished. The status of the condition should be False.
+const ReasonEvaluationComplete string = "EvaluationComplete"
+
+// ReasonEnforcementFailed should be used on the Degraded condition when the
+// policy is being enforced, but the controller was unable to remediate the
+// violations. The status of the condition should be True.
+const ReasonEnforcementFailed string = "EnforcementFailed"
+
+// ReasonAsExpected should be used on the Degraded condition when the controller
+// did everything the policy asked for. The status of the condition should be
+// False.
+const ReasonAsExpected string = "AsExpected"
+
 // UpdateCondition sets the Compliance condition in the given status to match
 // the ComplianceState, and to have the given reason and message. It will update
 // the LastTransitionTime if the status has changed, and it will initialize the
-// condition if it did not already exist.
+// condition if it did not already exist. It also records the change in the
+// History of the status with AppendHistory, keeping up to MaxHistoryLength
+// entries, so a change to only the message is not recorded there. The
+// ObservedGeneration of the condition is left alone, since the status alone
+// does not show which generation of the policy was evaluated: use
+// UpdatePolicyCondition after an evaluation, so that it is set correctly.
 func UpdateCondition(status *PolicyTypeStatus, reason, msg string) {
 	compCond := meta.FindStatusCondition(status.Conditions, ComplianceConditionType)
 	if compCond == nil {
@@ -70,4 +120,136 @@ func UpdateCondition(status *PolicyTypeStatus, reason, msg string) {
 	compCond.Message = msg
 
 	meta.SetStatusCondition(&status.Conditions, *compCond)
+
+	AppendHistory(status, HistoryEntry{
+		Timestamp:       metav1.Now(),
+		ComplianceState: status.ComplianceState,
+		Reason:          reason,
+		Message:         msg,
+	}, MaxHistoryLength)
+}
+
+// UpdatePolicyCondition updates the Compliance condition with UpdateCondition,
+// and sets the ObservedGeneration of both the status and the condition to the
+// current generation of the policy. It should be used after the policy has been
+// evaluated, so that IsStatusCurrent can tell that the status is up to date.
+func UpdatePolicyCondition(policy PolicyTyper, reason, msg string) {
+	status := policy.PolicyStatus()
+	status.ObservedGeneration = policy.GetGeneration()
+
+	UpdateCondition(status, reason, msg)
+
+	compCond := meta.FindStatusCondition(status.Conditions, ComplianceConditionType)
+	compCond.ObservedGeneration = status.ObservedGeneration
+}
+
+// IsStatusCurrent returns true if the status of the policy reflects its current
+// spec: both the ObservedGeneration of the status and of the Compliance
+// condition must match the generation of the policy. Consumers can use this to
+// hide compliance results which are stale.
+func IsStatusCurrent(policy PolicyTyper) bool {
+	status := policy.PolicyStatus()
+	if status.ObservedGeneration != policy.GetGeneration() {
+		return false
+	}
+
+	compCond := meta.FindStatusCondition(status.Conditions, ComplianceConditionType)
+
+	return compCond != nil && compCond.ObservedGeneration == policy.GetGeneration()
+}
+
+// SetReadyCondition sets the Ready condition in the given status, to True if
+// the policy could be evaluated and False otherwise, with the given reason and
+// message. The ObservedGeneration of the condition is set from the
+// ObservedGeneration of the status.
+func SetReadyCondition(status *PolicyTypeStatus, ready bool, reason, msg string) {
+	setCondition(status, ReadyConditionType, ready, reason, msg)
+}
+
+// SetEvaluatingCondition sets the Evaluating condition in the given status, to
+// True if an evaluation is in progress and False otherwise, with the given
+// reason and message. The ObservedGeneration of the condition is set from the
+// ObservedGeneration of the status.
+func SetEvaluatingCondition(status *PolicyTypeStatus, evaluating bool, reason, msg string) {
+	setCondition(status, EvaluatingConditionType, evaluating, reason, msg)
+}
+
+// SetDegradedCondition sets the Degraded condition in the given status, to
+// True if the controller failed to do something the policy asked for and False
+// otherwise, with the given reason and message. The ObservedGeneration of the
+// condition is set from the ObservedGeneration of the status.
+func SetDegradedCondition(status *PolicyTypeStatus, degraded bool, reason, msg string) {
+	setCondition(status, DegradedConditionType, degraded, reason, msg)
+}
+
+func setCondition(status *PolicyTypeStatus, condType string, isTrue bool, reason, msg string) {
+	cond := metav1.Condition{
+		Type:               condType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: status.ObservedGeneration,
+	}
+
+	if isTrue {
+		cond.Status = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(&status.Conditions, cond)
+}
+
+// Phase is a summary of the conditions of a policy, for display purposes.
+type Phase string
+
+const (
+	// PhasePending means the policy has not been evaluated yet.
+	PhasePending Phase = "Pending"
+	// PhaseError means the controller was unable to evaluate the policy.
+	PhaseError Phase = "Error"
+	// PhaseEvaluating means an evaluation of the policy is in progress.
+	PhaseEvaluating Phase = "Evaluating"
+	// PhaseDegraded means the policy was evaluated, but the controller failed
+	// to do something the policy asked for, like enforcing it.
+	PhaseDegraded Phase = "Degraded"
+	// PhaseCompliant means the policy was evaluated and is compliant.
+	PhaseCompliant Phase = "Compliant"
+	// PhaseNonCompliant means the policy was evaluated and is not compliant.
+	PhaseNonCompliant Phase = "NonCompliant"
+	// PhaseUnknown means the policy was evaluated, but its compliance is not
+	// known.
+	PhaseUnknown Phase = "Unknown"
+)
+
+// SummaryPhase derives a single Phase from the conditions of a policy. The
+// conditions are considered in order of importance: a Ready condition which is
+// False means PhaseError, an Evaluating condition which is True means
+// PhaseEvaluating, and a Degraded condition which is True means PhaseDegraded.
+// Otherwise, the phase is based on the Compliant condition, or is PhasePending
+// if that condition has not been set.
+func SummaryPhase(conditions []metav1.Condition) Phase {
+	if meta.IsStatusConditionFalse(conditions, ReadyConditionType) {
+		return PhaseError
+	}
+
+	if meta.IsStatusConditionTrue(conditions, EvaluatingConditionType) {
+		return PhaseEvaluating
+	}
+
+	if meta.IsStatusConditionTrue(conditions, DegradedConditionType) {
+		return PhaseDegraded
+	}
+
+	compCond := meta.FindStatusCondition(conditions, ComplianceConditionType)
+	if compCond == nil {
+		return PhasePending
+	}
+
+	switch compCond.Status {
+	case metav1.ConditionTrue:
+		return PhaseCompliant
+	case metav1.ConditionFalse:
+		return PhaseNonCompliant
+	default:
+		return PhaseUnknown
+	}
 }
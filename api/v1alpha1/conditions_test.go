@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdatePolicyCondition(t *testing.T) {
+	policy := &PolicyType{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Generation: 3},
+		Status:     PolicyTypeStatus{ComplianceState: Compliant},
+	}
+
+	UpdatePolicyCondition(policy, ReasonPolicyCompliant, "all good")
+
+	if policy.Status.ObservedGeneration != 3 {
+		t.Errorf("expected status observedGeneration: 3, got: %v", policy.Status.ObservedGeneration)
+	}
+
+	compCond := meta.FindStatusCondition(policy.Status.Conditions, ComplianceConditionType)
+	if compCond == nil || compCond.ObservedGeneration != 3 {
+		t.Errorf("expected condition observedGeneration: 3, got: %+v", compCond)
+	}
+}
+
+func TestUpdateConditionKeepsObservedGeneration(t *testing.T) {
+	status := &PolicyTypeStatus{
+		ComplianceState:    NonCompliant,
+		ObservedGeneration: 5,
+		Conditions: []metav1.Condition{{
+			Type:               ComplianceConditionType,
+			Status:             metav1.ConditionTrue,
+			Reason:             ReasonPolicyCompliant,
+			ObservedGeneration: 2,
+		}},
+	}
+
+	UpdateCondition(status, ReasonViolationsFound, "missing")
+
+	compCond := meta.FindStatusCondition(status.Conditions, ComplianceConditionType)
+	if compCond == nil || compCond.Status != metav1.ConditionFalse || compCond.ObservedGeneration != 2 {
+		t.Errorf("expected a False condition with observedGeneration: 2, got: %+v", compCond)
+	}
+}
+
+func TestIsStatusCurrent(t *testing.T) {
+	type test struct {
+		name          string
+		generation    int64
+		statusGen     int64
+		conditionGen  int64
+		withCondition bool
+		want          bool
+	}
+
+	tests := []test{
+		{"current", 2, 2, 2, true, true},
+		{"spec changed", 3, 2, 2, true, false},
+		{"condition is stale", 2, 2, 1, true, false},
+		{"no condition", 2, 2, 0, false, false},
+		{"never evaluated", 1, 0, 0, false, false},
+	}
+
+	for _, tc := range tests {
+		policy := &PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Generation: tc.generation},
+			Status:     PolicyTypeStatus{ObservedGeneration: tc.statusGen},
+		}
+
+		if tc.withCondition {
+			policy.Status.Conditions = []metav1.Condition{{
+				Type:               ComplianceConditionType,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: tc.conditionGen,
+			}}
+		}
+
+		if got := IsStatusCurrent(policy); got != tc.want {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSummaryPhase(t *testing.T) {
+	type test struct {
+		name  string
+		setup func(status *PolicyTypeStatus)
+		want  Phase
+	}
+
+	tests := []test{
+		{
+			name:  "no conditions",
+			setup: func(status *PolicyTypeStatus) {},
+			want:  PhasePending,
+		}, {
+			name: "compliant",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = Compliant
+				UpdateCondition(status, ReasonPolicyCompliant, "")
+				SetReadyCondition(status, true, ReasonEvaluated, "")
+			},
+			want: PhaseCompliant,
+		}, {
+			name: "noncompliant",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = NonCompliant
+				UpdateCondition(status, ReasonViolationsFound, "")
+				SetReadyCondition(status, true, ReasonEvaluated, "")
+				SetDegradedCondition(status, false, ReasonAsExpected, "")
+			},
+			want: PhaseNonCompliant,
+		}, {
+			name: "unknown compliance",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = UnknownCompliancy
+				UpdateCondition(status, ReasonPolicyError, "")
+			},
+			want: PhaseUnknown,
+		}, {
+			name: "not ready",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = UnknownCompliancy
+				UpdateCondition(status, ReasonPolicyError, "")
+				SetReadyCondition(status, false, ReasonPolicyError, "boom")
+				SetEvaluatingCondition(status, true, ReasonEvaluationInProgress, "")
+			},
+			want: PhaseError,
+		}, {
+			name: "evaluating",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = Compliant
+				UpdateCondition(status, ReasonPolicyCompliant, "")
+				SetEvaluatingCondition(status, true, ReasonEvaluationInProgress, "")
+				SetDegradedCondition(status, true, ReasonEnforcementFailed, "")
+			},
+			want: PhaseEvaluating,
+		}, {
+			name: "degraded",
+			setup: func(status *PolicyTypeStatus) {
+				status.ComplianceState = NonCompliant
+				UpdateCondition(status, ReasonViolationsFound, "")
+				SetEvaluatingCondition(status, false, ReasonEvaluationComplete, "")
+				SetDegradedCondition(status, true, ReasonEnforcementFailed, "")
+			},
+			want: PhaseDegraded,
+		},
+	}
+
+	for _, tc := range tests {
+		status := &PolicyTypeStatus{}
+		tc.setup(status)
+
+		if got := SummaryPhase(status.Conditions); got != tc.want {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestSetConditionObservedGeneration(t *testing.T) {
+	status := &PolicyTypeStatus{ObservedGeneration: 4}
+
+	SetDegradedCondition(status, true, ReasonEnforcementFailed, "could not patch")
+
+	cond := meta.FindStatusCondition(status.Conditions, DegradedConditionType)
+	if cond == nil {
+		t.Fatal("Degraded condition was not set")
+	}
+
+	if cond.Status != metav1.ConditionTrue || cond.Reason != ReasonEnforcementFailed || cond.ObservedGeneration != 4 {
+		t.Errorf("unexpected Degraded condition: %+v", cond)
+	}
+}
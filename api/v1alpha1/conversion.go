@@ -0,0 +1,318 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+)
+
+// blank assignment to verify that PolicyType implements Convertible
+var _ conversion.Convertible = &PolicyType{}
+
+// ConvertTo converts this PolicyType to the hub version (v1beta1).
+func (src *PolicyType) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.PolicyType)
+	if !ok {
+		return fmt.Errorf("expected a *v1beta1.PolicyType but got a %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	ConvertSpecToV1beta1(&src.Spec, &dst.Spec)
+	ConvertStatusToV1beta1(&src.Status, &dst.Status)
+
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this PolicyType.
+func (dst *PolicyType) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.PolicyType)
+	if !ok {
+		return fmt.Errorf("expected a *v1beta1.PolicyType but got a %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	ConvertSpecFromV1beta1(&src.Spec, &dst.Spec)
+	ConvertStatusFromV1beta1(&src.Status, &dst.Status)
+
+	return nil
+}
+
+// ConvertSpecToV1beta1 copies the fields of a v1alpha1 PolicyTypeSpec into a
+// v1beta1 PolicyTypeSpec. Policy types which embed the PolicyTypeSpec can use
+// this in their own ConvertTo implementations. The Severity and
+// RemediationAction are converted to their canonical lowercase forms, which are
+// the only forms accepted in v1beta1.
+func ConvertSpecToV1beta1(src *PolicyTypeSpec, dst *v1beta1.PolicyTypeSpec) {
+	dst.Severity = v1beta1.Severity(src.GetSeverity())
+	dst.RemediationAction = v1beta1.RemediationAction(src.GetRemediationAction())
+
+	dst.NamespaceSelector = v1beta1.NamespaceSelector{
+		Include:          toV1beta1Strings(src.NamespaceSelector.Include),
+		Exclude:          toV1beta1Strings(src.NamespaceSelector.Exclude),
+		MatchLabels:      copyStringMap(src.NamespaceSelector.MatchLabels),
+		MatchExpressions: copyRequirements(src.NamespaceSelector.MatchExpressions),
+	}
+
+	dst.LabelSelector = nil
+	if src.LabelSelector != nil {
+		dst.LabelSelector = make(map[string]v1beta1.NonEmptyString, len(src.LabelSelector))
+		for key, val := range src.LabelSelector {
+			dst.LabelSelector[key] = v1beta1.NonEmptyString(val)
+		}
+	}
+
+	dst.EvaluationInterval = v1beta1.EvaluationInterval(src.EvaluationInterval)
+}
+
+// ConvertSpecFromV1beta1 copies the fields of a v1beta1 PolicyTypeSpec into a
+// v1alpha1 PolicyTypeSpec. Policy types which embed the PolicyTypeSpec can use
+// this in their own ConvertFrom implementations.
+func ConvertSpecFromV1beta1(src *v1beta1.PolicyTypeSpec, dst *PolicyTypeSpec) {
+	dst.Severity = string(src.Severity)
+	dst.RemediationAction = string(src.RemediationAction)
+
+	dst.NamespaceSelector = NamespaceSelector{
+		Include:          fromV1beta1Strings(src.NamespaceSelector.Include),
+		Exclude:          fromV1beta1Strings(src.NamespaceSelector.Exclude),
+		MatchLabels:      copyStringMap(src.NamespaceSelector.MatchLabels),
+		MatchExpressions: copyRequirements(src.NamespaceSelector.MatchExpressions),
+	}
+
+	dst.LabelSelector = nil
+	if src.LabelSelector != nil {
+		dst.LabelSelector = make(map[string]NonEmptyString, len(src.LabelSelector))
+		for key, val := range src.LabelSelector {
+			dst.LabelSelector[key] = NonEmptyString(val)
+		}
+	}
+
+	dst.EvaluationInterval = EvaluationInterval(src.EvaluationInterval)
+}
+
+// ConvertStatusToV1beta1 copies the fields of a v1alpha1 PolicyTypeStatus into
+// a v1beta1 PolicyTypeStatus. Policy types which embed the PolicyTypeStatus can
+// use this in their own ConvertTo implementations.
+func ConvertStatusToV1beta1(src *PolicyTypeStatus, dst *v1beta1.PolicyTypeStatus) {
+	dst.ComplianceState = v1beta1.ComplianceState(src.ComplianceState)
+	dst.CompliancyDetails = convertDetailsToV1beta1(src.CompliancyDetails)
+
+	dst.RelatedObjects = nil
+	if src.RelatedObjects != nil {
+		dst.RelatedObjects = make([]v1beta1.RelatedObject, len(src.RelatedObjects))
+		for i, obj := range src.RelatedObjects {
+			dst.RelatedObjects[i] = v1beta1.RelatedObject{
+				Object: v1beta1.ObjectRef{
+					TypeMeta: obj.Object.TypeMeta,
+					Metadata: v1beta1.ObjectMetadata(obj.Object.Metadata),
+				},
+				ComplianceState: v1beta1.ComplianceState(obj.ComplianceState),
+				Reason:          v1beta1.NonEmptyString(obj.Reason),
+				Diff:            obj.Diff,
+			}
+		}
+	}
+
+	dst.Conditions = copyConditions(src.Conditions)
+
+	dst.History = nil
+	if src.History != nil {
+		dst.History = make([]v1beta1.HistoryEntry, len(src.History))
+		for i, entry := range src.History {
+			dst.History[i] = v1beta1.HistoryEntry{
+				Timestamp:       *entry.Timestamp.DeepCopy(),
+				ComplianceState: v1beta1.ComplianceState(entry.ComplianceState),
+				Reason:          entry.Reason,
+				Message:         entry.Message,
+			}
+		}
+	}
+
+	dst.LastEvaluated = *src.LastEvaluated.DeepCopy()
+	dst.ObservedGeneration = src.ObservedGeneration
+}
+
+// ConvertStatusFromV1beta1 copies the fields of a v1beta1 PolicyTypeStatus into
+// a v1alpha1 PolicyTypeStatus. Policy types which embed the PolicyTypeStatus can
+// use this in their own ConvertFrom implementations.
+func ConvertStatusFromV1beta1(src *v1beta1.PolicyTypeStatus, dst *PolicyTypeStatus) {
+	dst.ComplianceState = ComplianceState(src.ComplianceState)
+	dst.CompliancyDetails = convertDetailsFromV1beta1(src.CompliancyDetails)
+
+	dst.RelatedObjects = nil
+	if src.RelatedObjects != nil {
+		dst.RelatedObjects = make([]RelatedObject, len(src.RelatedObjects))
+		for i, obj := range src.RelatedObjects {
+			dst.RelatedObjects[i] = RelatedObject{
+				Object: ObjectRef{
+					TypeMeta: obj.Object.TypeMeta,
+					Metadata: ObjectMetadata(obj.Object.Metadata),
+				},
+				ComplianceState: ComplianceState(obj.ComplianceState),
+				Reason:          NonEmptyString(obj.Reason),
+				Diff:            obj.Diff,
+			}
+		}
+	}
+
+	dst.Conditions = copyConditions(src.Conditions)
+
+	dst.History = nil
+	if src.History != nil {
+		dst.History = make([]HistoryEntry, len(src.History))
+		for i, entry := range src.History {
+			dst.History[i] = HistoryEntry{
+				Timestamp:       *entry.Timestamp.DeepCopy(),
+				ComplianceState: ComplianceState(entry.ComplianceState),
+				Reason:          entry.Reason,
+				Message:         entry.Message,
+			}
+		}
+	}
+
+	dst.LastEvaluated = *src.LastEvaluated.DeepCopy()
+	dst.ObservedGeneration = src.ObservedGeneration
+}
+
+func convertDetailsToV1beta1(src CompliancyDetails) v1beta1.CompliancyDetails {
+	dst := v1beta1.CompliancyDetails{Version: src.Version}
+
+	if src.Templates != nil {
+		dst.Templates = make([]v1beta1.TemplateDetails, len(src.Templates))
+		for i, tmpl := range src.Templates {
+			dst.Templates[i] = v1beta1.TemplateDetails{
+				Name:            tmpl.Name,
+				ComplianceState: v1beta1.ComplianceState(tmpl.ComplianceState),
+			}
+
+			if tmpl.Conditions != nil {
+				dst.Templates[i].Conditions = make([]v1beta1.TemplateCondition, len(tmpl.Conditions))
+				for j := range tmpl.Conditions {
+					dst.Templates[i].Conditions[j] = v1beta1.TemplateCondition(*tmpl.Conditions[j].DeepCopy())
+				}
+			}
+
+			if tmpl.History != nil {
+				dst.Templates[i].History = make([]v1beta1.ComplianceHistory, len(tmpl.History))
+				for j := range tmpl.History {
+					dst.Templates[i].History[j] = v1beta1.ComplianceHistory(*tmpl.History[j].DeepCopy())
+				}
+			}
+		}
+	}
+
+	return dst
+}
+
+func convertDetailsFromV1beta1(src v1beta1.CompliancyDetails) CompliancyDetails {
+	dst := CompliancyDetails{Version: src.Version}
+
+	if src.Templates != nil {
+		dst.Templates = make([]TemplateDetails, len(src.Templates))
+		for i, tmpl := range src.Templates {
+			dst.Templates[i] = TemplateDetails{
+				Name:            tmpl.Name,
+				ComplianceState: ComplianceState(tmpl.ComplianceState),
+			}
+
+			if tmpl.Conditions != nil {
+				dst.Templates[i].Conditions = make([]TemplateCondition, len(tmpl.Conditions))
+				for j := range tmpl.Conditions {
+					dst.Templates[i].Conditions[j] = TemplateCondition(*tmpl.Conditions[j].DeepCopy())
+				}
+			}
+
+			if tmpl.History != nil {
+				dst.Templates[i].History = make([]ComplianceHistory, len(tmpl.History))
+				for j := range tmpl.History {
+					dst.Templates[i].History[j] = ComplianceHistory(*tmpl.History[j].DeepCopy())
+				}
+			}
+		}
+	}
+
+	return dst
+}
+
+func toV1beta1Strings(in []NonEmptyString) []v1beta1.NonEmptyString {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]v1beta1.NonEmptyString, len(in))
+	for i, s := range in {
+		out[i] = v1beta1.NonEmptyString(s)
+	}
+
+	return out
+}
+
+func fromV1beta1Strings(in []v1beta1.NonEmptyString) []NonEmptyString {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]NonEmptyString, len(in))
+	for i, s := range in {
+		out[i] = NonEmptyString(s)
+	}
+
+	return out
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(in))
+	for key, val := range in {
+		out[key] = val
+	}
+
+	return out
+}
+
+func copyRequirements(in []metav1.LabelSelectorRequirement) []metav1.LabelSelectorRequirement {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]metav1.LabelSelectorRequirement, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+
+	return out
+}
+
+func copyConditions(in []metav1.Condition) []metav1.Condition {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]metav1.Condition, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+
+	return out
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+)
+
+func TestConversionRoundTrip(t *testing.T) {
+	original := &PolicyType{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Labels: map[string]string{"a": "b"}},
+		Spec: PolicyTypeSpec{
+			Severity:          "high",
+			RemediationAction: "enforce",
+			NamespaceSelector: NamespaceSelector{
+				Include:     []NonEmptyString{"*"},
+				Exclude:     []NonEmptyString{"kube-*", "!kube-public"},
+				MatchLabels: map[string]string{"env": "prod"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "team",
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{"payments"},
+				}},
+			},
+			LabelSelector:      map[string]NonEmptyString{"app": "foo"},
+			EvaluationInterval: EvaluationInterval{Compliant: "10m", NonCompliant: "never"},
+		},
+		Status: PolicyTypeStatus{
+			ComplianceState:    NonCompliant,
+			LastEvaluated:      metav1.NewTime(time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)),
+			ObservedGeneration: 2,
+			CompliancyDetails: CompliancyDetails{
+				Version: CompliancyDetailsVersion,
+				Templates: []TemplateDetails{{
+					Name:            "0",
+					ComplianceState: NonCompliant,
+					Conditions: []TemplateCondition{{
+						Type:    TemplateConditionViolation,
+						Status:  metav1.ConditionTrue,
+						Reason:  "K8s missing a must have object",
+						Message: "configmaps [foo] not found in namespace default",
+					}},
+					History: []ComplianceHistory{{
+						Message:   "NonCompliant; configmaps [foo] not found in namespace default",
+						EventName: "parent.1",
+					}},
+				}},
+			},
+			RelatedObjects: []RelatedObject{{
+				Object: ObjectRef{
+					TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+					Metadata: ObjectMetadata{Name: "foo", Namespace: "default"},
+				},
+				ComplianceState: NonCompliant,
+				Reason:          "missing",
+			}},
+			History: []HistoryEntry{{
+				Timestamp:       metav1.NewTime(time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)),
+				ComplianceState: NonCompliant,
+				Reason:          ReasonViolationsFound,
+				Message:         "missing",
+			}},
+			Conditions: []metav1.Condition{{
+				Type:   ComplianceConditionType,
+				Status: metav1.ConditionFalse,
+				Reason: ReasonViolationsFound,
+			}},
+		},
+	}
+
+	hub := &v1beta1.PolicyType{}
+	if err := original.DeepCopy().ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+
+	if hub.Spec.Severity != v1beta1.SeverityHigh {
+		t.Errorf("expected severity: %v, got: %v", v1beta1.SeverityHigh, hub.Spec.Severity)
+	}
+	if hub.Status.ComplianceState != v1beta1.NonCompliant {
+		t.Errorf("expected compliance: %v, got: %v", v1beta1.NonCompliant, hub.Status.ComplianceState)
+	}
+
+	converted := &PolicyType{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(original, converted) {
+		t.Errorf("round trip changed the policy:\noriginal:  %+v\nconverted: %+v", original, converted)
+	}
+}
+
+func TestConvertSpecNormalizesCasing(t *testing.T) {
+	src := &PolicyTypeSpec{Severity: "Critical", RemediationAction: "Inform"}
+	dst := &v1beta1.PolicyTypeSpec{}
+
+	ConvertSpecToV1beta1(src, dst)
+
+	if dst.Severity != v1beta1.SeverityCritical {
+		t.Errorf("expected severity: %v, got: %v", v1beta1.SeverityCritical, dst.Severity)
+	}
+	if dst.RemediationAction != v1beta1.RemediationInform {
+		t.Errorf("expected remediationAction: %v, got: %v", v1beta1.RemediationInform, dst.RemediationAction)
+	}
+}
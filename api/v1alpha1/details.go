@@ -0,0 +1,191 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CompliancyDetailsVersion is the version of the CompliancyDetails format
+// defined in this package.
+const CompliancyDetailsVersion = "v1"
+
+// Types of TemplateConditions, matching the ones used by configuration-policy.
+const (
+	TemplateConditionViolation    = "violation"
+	TemplateConditionNotification = "notification"
+)
+
+// LegacyTemplateStatus is one item of the list of compliancy details used by
+// configuration-policy, which has one item per object-template in the policy.
+//+kubebuilder:object:generate=false
+type LegacyTemplateStatus struct {
+	ComplianceState ComplianceState     `json:"Compliant,omitempty"`
+	Validity        LegacyValidity      `json:"Validity,omitempty"`
+	Conditions      []TemplateCondition `json:"conditions,omitempty"`
+}
+
+// LegacyValidity reports whether a configuration-policy template was valid.
+//+kubebuilder:object:generate=false
+type LegacyValidity struct {
+	Valid  *bool  `json:"valid,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LegacyDetailsMap is the map of compliancy details used by iam-policy. Each
+// outer key identifies a template, and each inner key identifies a group of
+// violation messages.
+//+kubebuilder:object:generate=false
+type LegacyDetailsMap map[string]map[string][]string
+
+// DetailsFromList converts the list of compliancy details used by
+// configuration-policy into CompliancyDetails. Each template is named by its
+// index in the list, and an invalid template gets an extra violation condition
+// with the reason it was invalid.
+func DetailsFromList(list []LegacyTemplateStatus) CompliancyDetails {
+	details := CompliancyDetails{Version: CompliancyDetailsVersion}
+
+	for i, item := range list {
+		tmpl := TemplateDetails{
+			Name:            strconv.Itoa(i),
+			ComplianceState: item.ComplianceState,
+		}
+
+		if item.Validity.Valid != nil && !*item.Validity.Valid {
+			tmpl.Conditions = append(tmpl.Conditions, TemplateCondition{
+				Type:    TemplateConditionViolation,
+				Status:  metav1.ConditionTrue,
+				Reason:  "Invalid template",
+				Message: item.Validity.Reason,
+			})
+		}
+
+		for _, cond := range item.Conditions {
+			tmpl.Conditions = append(tmpl.Conditions, *cond.DeepCopy())
+		}
+
+		details.Templates = append(details.Templates, tmpl)
+	}
+
+	return details
+}
+
+// DetailsFromMap converts the map of compliancy details used by iam-policy
+// into CompliancyDetails. Each outer key becomes a template, and each message
+// becomes a violation condition with the inner key as its reason. A template
+// with no messages is compliant. Templates and conditions are sorted by key, so
+// the result is stable.
+func DetailsFromMap(legacy LegacyDetailsMap) CompliancyDetails {
+	details := CompliancyDetails{Version: CompliancyDetailsVersion}
+
+	names := make([]string, 0, len(legacy))
+	for name := range legacy {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		tmpl := TemplateDetails{Name: name, ComplianceState: Compliant}
+
+		groups := legacy[name]
+		for _, group := range sortedKeys(groups) {
+			for _, msg := range groups[group] {
+				tmpl.ComplianceState = NonCompliant
+				tmpl.Conditions = append(tmpl.Conditions, TemplateCondition{
+					Type:    TemplateConditionViolation,
+					Status:  metav1.ConditionTrue,
+					Reason:  group,
+					Message: msg,
+				})
+			}
+		}
+
+		details.Templates = append(details.Templates, tmpl)
+	}
+
+	return details
+}
+
+// ParseCompliancyDetails parses the JSON of a compliancyDetails field from any
+// policy type: the CompliancyDetails format, the list used by
+// configuration-policy, or the map used by iam-policy. The legacy shapes are
+// converted with DetailsFromList and DetailsFromMap.
+func ParseCompliancyDetails(raw []byte) (CompliancyDetails, error) {
+	raw = bytes.TrimSpace(raw)
+
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return CompliancyDetails{Version: CompliancyDetailsVersion}, nil
+	}
+
+	switch raw[0] {
+	case '[':
+		list := make([]LegacyTemplateStatus, 0)
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return CompliancyDetails{}, fmt.Errorf("unable to parse the compliancy details list: %w", err)
+		}
+
+		return DetailsFromList(list), nil
+	case '{':
+		fields := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return CompliancyDetails{}, fmt.Errorf("unable to parse the compliancy details: %w", err)
+		}
+
+		_, hasVersion := fields["version"]
+		_, hasTemplates := fields["templates"]
+
+		if hasVersion || hasTemplates {
+			details := CompliancyDetails{}
+			if err := json.Unmarshal(raw, &details); err != nil {
+				return CompliancyDetails{}, fmt.Errorf("unable to parse the compliancy details: %w", err)
+			}
+
+			if details.Version == "" {
+				details.Version = CompliancyDetailsVersion
+			}
+
+			return details, nil
+		}
+
+		legacy := make(LegacyDetailsMap)
+		if err := json.Unmarshal(raw, &legacy); err != nil {
+			return CompliancyDetails{}, fmt.Errorf("unable to parse the compliancy details map: %w", err)
+		}
+
+		return DetailsFromMap(legacy), nil
+	}
+
+	return CompliancyDetails{}, fmt.Errorf("unable to parse the compliancy details: unexpected JSON %q", raw)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
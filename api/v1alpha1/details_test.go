@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseCompliancyDetails(t *testing.T) {
+	type test struct {
+		name    string
+		raw     string
+		want    CompliancyDetails
+		wantErr bool
+	}
+
+	violation := func(reason, msg string) TemplateCondition {
+		return TemplateCondition{
+			Type:    TemplateConditionViolation,
+			Status:  metav1.ConditionTrue,
+			Reason:  reason,
+			Message: msg,
+		}
+	}
+
+	tests := []test{
+		{
+			name: "empty",
+			raw:  "",
+			want: CompliancyDetails{Version: CompliancyDetailsVersion},
+		}, {
+			name: "current format",
+			raw:  `{"version":"v1","templates":[{"name":"a","compliant":"Compliant"}]}`,
+			want: CompliancyDetails{
+				Version:   "v1",
+				Templates: []TemplateDetails{{Name: "a", ComplianceState: Compliant}},
+			},
+		}, {
+			name: "configuration-policy list",
+			raw: `[
+				{"Compliant":"NonCompliant","Validity":{},"conditions":[{"type":"violation","status":"True",
+					"reason":"K8s missing a must have object","message":"pods [nginx] not found"}]},
+				{"Compliant":"NonCompliant","Validity":{"valid":false,"reason":"bad template"}}
+			]`,
+			want: CompliancyDetails{
+				Version: CompliancyDetailsVersion,
+				Templates: []TemplateDetails{{
+					Name:            "0",
+					ComplianceState: NonCompliant,
+					Conditions:      []TemplateCondition{violation("K8s missing a must have object", "pods [nginx] not found")},
+				}, {
+					Name:            "1",
+					ComplianceState: NonCompliant,
+					Conditions:      []TemplateCondition{violation("Invalid template", "bad template")},
+				}},
+			},
+		}, {
+			name: "iam-policy map",
+			raw:  `{"local-cluster":{"b":["second"],"a":["first"]},"other":{}}`,
+			want: CompliancyDetails{
+				Version: CompliancyDetailsVersion,
+				Templates: []TemplateDetails{{
+					Name:            "local-cluster",
+					ComplianceState: NonCompliant,
+					Conditions:      []TemplateCondition{violation("a", "first"), violation("b", "second")},
+				}, {
+					Name:            "other",
+					ComplianceState: Compliant,
+				}},
+			},
+		}, {
+			name:    "unexpected json",
+			raw:     `"foo"`,
+			wantErr: true,
+		}, {
+			name:    "invalid list",
+			raw:     `[1, 2]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		got, err := ParseCompliancyDetails([]byte(tc.raw))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+			continue
+		}
+
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("test '%v' expected: %+v, got: %+v", tc.name, tc.want, got)
+		}
+	}
+}
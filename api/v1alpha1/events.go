@@ -0,0 +1,201 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// compliantPrefix and nonCompliantPrefix start the message of every
+	// compliance event, as required by the policy framework.
+	compliantPrefix    = "Compliant; "
+	nonCompliantPrefix = "NonCompliant; "
+
+	// eventReasonPrefix starts the reason of every compliance event, and is
+	// followed by the namespace and name of the policy which sent it.
+	eventReasonPrefix = "policy: "
+)
+
+// eventAnnotationPrefix is the prefix of the compliance event annotations,
+// matching the API group of the policy framework.
+const eventAnnotationPrefix = "policy.open-cluster-management.io/"
+
+// Annotations added to compliance events by RecordAnnotatedComplianceEvent.
+const (
+	EventAnnotationComplianceState     = eventAnnotationPrefix + "compliance-state"
+	EventAnnotationSeverity            = eventAnnotationPrefix + "severity"
+	EventAnnotationGeneration          = eventAnnotationPrefix + "policy-generation"
+	EventAnnotationRelatedObjects      = eventAnnotationPrefix + "related-objects"
+	EventAnnotationNonCompliantRelated = eventAnnotationPrefix + "noncompliant-related-objects"
+	EventAnnotationEvaluationTimestamp = eventAnnotationPrefix + "evaluation-timestamp"
+)
+
+// ComplianceEventDetails is the information in a compliance event, as returned
+// by ParseComplianceEvent. Fields which come from annotations are only set if
+// the event was created by RecordAnnotatedComplianceEvent.
+//+kubebuilder:object:generate=false
+type ComplianceEventDetails struct {
+	// PolicyNamespace and PolicyName identify the policy which sent the event.
+	PolicyNamespace string
+	PolicyName      string
+
+	// ComplianceState is the compliance reported by the event. For events
+	// without annotations, it is only ever Compliant or NonCompliant.
+	ComplianceState ComplianceState
+
+	// Message is the message of the event, without the compliance prefix.
+	Message string
+
+	// Annotated is true when the event had the structured annotations, and the
+	// rest of the fields were parsed from them.
+	Annotated                  bool
+	Severity                   Severity
+	Generation                 int64
+	RelatedObjects             int
+	NonCompliantRelatedObjects int
+	EvaluationTimestamp        time.Time
+}
+
+// RecordAnnotatedComplianceEvent works like RecordComplianceEvent, but also adds
+// annotations with structured information about the policy to the events, so
+// that they can be decoded with ParseComplianceEvent without relying on the
+// message format. The reason and message are the same as RecordComplianceEvent
+// uses, so consumers which only understand that format are not affected.
+func RecordAnnotatedComplianceEvent(r record.EventRecorder, policy PolicyTyper, msg string) error {
+	return recordComplianceEvent(r, policy, msg, ComplianceEventAnnotations(policy))
+}
+
+// ComplianceEventAnnotations returns the annotations which
+// RecordAnnotatedComplianceEvent adds to a compliance event for the policy.
+func ComplianceEventAnnotations(policy PolicyTyper) map[string]string {
+	status := policy.PolicyStatus()
+
+	nonCompliantRelated := 0
+	for _, obj := range status.RelatedObjects {
+		if obj.ComplianceState == NonCompliant {
+			nonCompliantRelated++
+		}
+	}
+
+	evaluated := status.LastEvaluated.Time
+	if evaluated.IsZero() {
+		evaluated = time.Now()
+	}
+
+	return map[string]string{
+		EventAnnotationComplianceState:     string(status.ComplianceState),
+		EventAnnotationSeverity:            string(policy.PolicySpec().GetSeverity()),
+		EventAnnotationGeneration:          strconv.FormatInt(policy.GetGeneration(), 10),
+		EventAnnotationRelatedObjects:      strconv.Itoa(len(status.RelatedObjects)),
+		EventAnnotationNonCompliantRelated: strconv.Itoa(nonCompliantRelated),
+		EventAnnotationEvaluationTimestamp: evaluated.UTC().Format(time.RFC3339),
+	}
+}
+
+// ParseComplianceEvent decodes a compliance event created by either
+// RecordComplianceEvent or RecordAnnotatedComplianceEvent. It returns an error
+// if the event is not a compliance event, or if an annotation is malformed.
+func ParseComplianceEvent(event *corev1.Event) (ComplianceEventDetails, error) {
+	details := ComplianceEventDetails{}
+
+	if !strings.HasPrefix(event.Reason, eventReasonPrefix) {
+		return details, fmt.Errorf("event reason %q does not start with %q", event.Reason, eventReasonPrefix)
+	}
+
+	policyRef := strings.TrimPrefix(event.Reason, eventReasonPrefix)
+
+	slash := strings.Index(policyRef, "/")
+	if slash == -1 {
+		return details, fmt.Errorf("event reason %q does not include the policy namespace and name", event.Reason)
+	}
+
+	details.PolicyNamespace = policyRef[:slash]
+	details.PolicyName = policyRef[slash+1:]
+
+	switch {
+	case strings.HasPrefix(event.Message, compliantPrefix):
+		details.ComplianceState = Compliant
+		details.Message = strings.TrimPrefix(event.Message, compliantPrefix)
+	case strings.HasPrefix(event.Message, nonCompliantPrefix):
+		details.ComplianceState = NonCompliant
+		details.Message = strings.TrimPrefix(event.Message, nonCompliantPrefix)
+	default:
+		return details, fmt.Errorf("event message %q does not start with a compliance prefix", event.Message)
+	}
+
+	state, found := event.Annotations[EventAnnotationComplianceState]
+	if !found {
+		return details, nil
+	}
+
+	details.Annotated = true
+	details.ComplianceState = ComplianceState(state)
+	details.Severity = Severity(event.Annotations[EventAnnotationSeverity])
+
+	var err error
+
+	details.Generation, err = parseIntAnnotation(event, EventAnnotationGeneration)
+	if err != nil {
+		return details, err
+	}
+
+	related, err := parseIntAnnotation(event, EventAnnotationRelatedObjects)
+	if err != nil {
+		return details, err
+	}
+
+	details.RelatedObjects = int(related)
+
+	nonCompliantRelated, err := parseIntAnnotation(event, EventAnnotationNonCompliantRelated)
+	if err != nil {
+		return details, err
+	}
+
+	details.NonCompliantRelatedObjects = int(nonCompliantRelated)
+
+	if timestamp := event.Annotations[EventAnnotationEvaluationTimestamp]; timestamp != "" {
+		details.EvaluationTimestamp, err = time.Parse(time.RFC3339, timestamp)
+		if err != nil {
+			return details, fmt.Errorf("invalid %v annotation: %w", EventAnnotationEvaluationTimestamp, err)
+		}
+	}
+
+	return details, nil
+}
+
+// parseIntAnnotation parses the annotation on the event as an integer. A
+// missing annotation is treated as zero.
+func parseIntAnnotation(event *corev1.Event, key string) (int64, error) {
+	val, found := event.Annotations[key]
+	if !found || val == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %v annotation: %w", key, err)
+	}
+
+	return parsed, nil
+}
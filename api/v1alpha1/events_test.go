@@ -0,0 +1,171 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// eventCapturer is an EventRecorder which keeps the events it receives as
+// corev1.Events, including any annotations.
+type eventCapturer struct {
+	events []*corev1.Event
+}
+
+func (c *eventCapturer) Event(object runtime.Object, eventtype, reason, message string) {
+	c.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (c *eventCapturer) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	c.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (c *eventCapturer) AnnotatedEventf(
+	object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{},
+) {
+	c.events = append(c.events, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		Type:       eventtype,
+		Reason:     reason,
+		Message:    fmt.Sprintf(messageFmt, args...),
+	})
+}
+
+func TestAnnotatedComplianceEventRoundTrip(t *testing.T) {
+	evaluated := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	policy := &PolicyType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "test",
+			Namespace:  "default",
+			Generation: 3,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "policy.open-cluster-management.io/v1",
+				Kind:       "Policy",
+				Name:       "parent",
+			}},
+		},
+		Spec: PolicyTypeSpec{Severity: "High"},
+		Status: PolicyTypeStatus{
+			ComplianceState: NonCompliant,
+			LastEvaluated:   metav1.NewTime(evaluated),
+			RelatedObjects: []RelatedObject{
+				{ComplianceState: NonCompliant},
+				{ComplianceState: Compliant},
+				{ComplianceState: NonCompliant},
+			},
+		},
+	}
+
+	recorder := &eventCapturer{}
+	if err := RecordAnnotatedComplianceEvent(recorder, policy, "100% wrong"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected 1 event, got: %v", len(recorder.events))
+	}
+
+	event := recorder.events[0]
+	if event.Message != "NonCompliant; 100% wrong" {
+		t.Errorf("expected the legacy message format, got: %q", event.Message)
+	}
+
+	got, err := ParseComplianceEvent(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := ComplianceEventDetails{
+		PolicyNamespace:            "default",
+		PolicyName:                 "test",
+		ComplianceState:            NonCompliant,
+		Message:                    "100% wrong",
+		Annotated:                  true,
+		Severity:                   SeverityHigh,
+		Generation:                 3,
+		RelatedObjects:             3,
+		NonCompliantRelatedObjects: 2,
+		EvaluationTimestamp:        evaluated,
+	}
+
+	if got != want {
+		t.Errorf("expected: %+v, got: %+v", want, got)
+	}
+}
+
+func TestParseComplianceEvent(t *testing.T) {
+	type test struct {
+		name    string
+		event   corev1.Event
+		want    ComplianceEventDetails
+		wantErr bool
+	}
+
+	tests := []test{
+		{
+			name:  "legacy compliant",
+			event: corev1.Event{Reason: "policy: default/foo", Message: "Compliant; all good"},
+			want: ComplianceEventDetails{
+				PolicyNamespace: "default", PolicyName: "foo", ComplianceState: Compliant, Message: "all good",
+			},
+		}, {
+			name:  "legacy noncompliant with semicolons in the message",
+			event: corev1.Event{Reason: "policy: ns/bar", Message: "NonCompliant; a; b"},
+			want: ComplianceEventDetails{
+				PolicyNamespace: "ns", PolicyName: "bar", ComplianceState: NonCompliant, Message: "a; b",
+			},
+		}, {
+			name:    "not a compliance event",
+			event:   corev1.Event{Reason: "Created", Message: "Compliant; ok"},
+			wantErr: true,
+		}, {
+			name:    "missing prefix",
+			event:   corev1.Event{Reason: "policy: default/foo", Message: "ok"},
+			wantErr: true,
+		}, {
+			name: "bad annotation",
+			event: corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+					EventAnnotationComplianceState: "Compliant",
+					EventAnnotationGeneration:      "three",
+				}},
+				Reason:  "policy: default/foo",
+				Message: "Compliant; ok",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		got, err := ParseComplianceEvent(&tc.event)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Errorf("test '%v' expected: %+v, got: %+v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -22,7 +22,22 @@ package v1alpha1
 
 import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
 )
 
-// GroupVersion is group version used to register these objects
-var GroupVersion = schema.GroupVersion{Group: "policy.open-cluster-management.io", Version: "v1alpha1"}
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "policy.open-cluster-management.io", Version: "v1alpha1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme. The
+	// framework's PolicyType is registered so that the conversion webhook of the
+	// PolicyType CRD in config/crd can be served with
+	// webhook.SetupConversionWithManager, which needs every version of the type
+	// in the scheme, and so that tools like policyctl can decode any policy as a
+	// PolicyType. Policy types which embed the framework structs still register
+	// their own kinds in their own scheme builders.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,55 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// MaxHistoryLength is the number of entries UpdateCondition keeps in the
+// History of a policy status. Setting it to 0 disables the history.
+var MaxHistoryLength = 10
+
+// AppendHistory adds the entry to the front of the History in the status, if
+// the ComplianceState or Reason is different from the most recent entry, and
+// then trims the History to at most maxLength entries, dropping the oldest
+// ones. If maxLength is less than 1, the History is cleared. It returns true
+// if the entry was added.
+//
+// The Message is not compared, so an entry which only has a different message
+// is not added, and the History keeps the message from when the state or reason
+// last changed. Messages often list the objects which violate the policy, and
+// comparing them would fill the History with entries that do not change the
+// compliance, pushing out the older transitions.
+func AppendHistory(status *PolicyTypeStatus, entry HistoryEntry, maxLength int) bool {
+	if maxLength < 1 {
+		status.History = nil
+
+		return false
+	}
+
+	added := false
+
+	if len(status.History) == 0 ||
+		status.History[0].ComplianceState != entry.ComplianceState ||
+		status.History[0].Reason != entry.Reason {
+		status.History = append([]HistoryEntry{entry}, status.History...)
+		added = true
+	}
+
+	if len(status.History) > maxLength {
+		status.History = status.History[:maxLength]
+	}
+
+	return added
+}
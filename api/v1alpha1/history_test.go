@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendHistory(t *testing.T) {
+	status := &PolicyTypeStatus{}
+
+	steps := []struct {
+		name       string
+		entry      HistoryEntry
+		maxLength  int
+		wantAdded  bool
+		wantStates []ComplianceState
+	}{
+		{
+			name:       "first entry",
+			entry:      HistoryEntry{ComplianceState: Compliant, Reason: ReasonPolicyCompliant},
+			maxLength:  3,
+			wantAdded:  true,
+			wantStates: []ComplianceState{Compliant},
+		}, {
+			name:       "only the message changed",
+			entry:      HistoryEntry{ComplianceState: Compliant, Reason: ReasonPolicyCompliant, Message: "new"},
+			maxLength:  3,
+			wantAdded:  false,
+			wantStates: []ComplianceState{Compliant},
+		}, {
+			name:       "state changed",
+			entry:      HistoryEntry{ComplianceState: NonCompliant, Reason: ReasonViolationsFound},
+			maxLength:  3,
+			wantAdded:  true,
+			wantStates: []ComplianceState{NonCompliant, Compliant},
+		}, {
+			name:       "reason changed",
+			entry:      HistoryEntry{ComplianceState: NonCompliant, Reason: ReasonNoCompliantObjects},
+			maxLength:  3,
+			wantAdded:  true,
+			wantStates: []ComplianceState{NonCompliant, NonCompliant, Compliant},
+		}, {
+			name:       "oldest entry trimmed",
+			entry:      HistoryEntry{ComplianceState: Compliant, Reason: ReasonPolicyCompliant},
+			maxLength:  3,
+			wantAdded:  true,
+			wantStates: []ComplianceState{Compliant, NonCompliant, NonCompliant},
+		}, {
+			name:       "shorter length trims without a change",
+			entry:      HistoryEntry{ComplianceState: Compliant, Reason: ReasonPolicyCompliant},
+			maxLength:  1,
+			wantAdded:  false,
+			wantStates: []ComplianceState{Compliant},
+		}, {
+			name:       "disabled",
+			entry:      HistoryEntry{ComplianceState: NonCompliant, Reason: ReasonViolationsFound},
+			maxLength:  0,
+			wantAdded:  false,
+			wantStates: []ComplianceState{},
+		},
+	}
+
+	for _, step := range steps {
+		added := AppendHistory(status, step.entry, step.maxLength)
+		if added != step.wantAdded {
+			t.Errorf("step '%v' expected added: %v, got: %v", step.name, step.wantAdded, added)
+		}
+
+		states := make([]ComplianceState, 0, len(status.History))
+		for _, entry := range status.History {
+			states = append(states, entry.ComplianceState)
+		}
+
+		if !reflect.DeepEqual(states, step.wantStates) {
+			t.Errorf("step '%v' expected history: %v, got: %v", step.name, step.wantStates, states)
+		}
+	}
+}
+
+func TestUpdateConditionHistory(t *testing.T) {
+	status := &PolicyTypeStatus{ComplianceState: NonCompliant}
+
+	UpdateCondition(status, ReasonViolationsFound, "first")
+	UpdateCondition(status, ReasonViolationsFound, "second")
+
+	status.ComplianceState = Compliant
+	UpdateCondition(status, ReasonPolicyCompliant, "fixed")
+
+	if len(status.History) != 2 {
+		t.Fatalf("expected 2 history entries, got: %+v", status.History)
+	}
+
+	if status.History[0].Message != "fixed" || status.History[1].Message != "first" {
+		t.Errorf("unexpected history messages: %+v", status.History)
+	}
+
+	if status.History[0].Timestamp.IsZero() {
+		t.Error("expected the history entry to have a timestamp")
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+)
+
+// IntervalNever can be used in an EvaluationInterval to indicate that the
+// policy should not be evaluated periodically while it has that compliance.
+const IntervalNever string = "never"
+
+// minRequeueAfter is returned by RequeueAfter when the next evaluation is
+// already due, since a zero duration would mean not to requeue at all.
+const minRequeueAfter = time.Second
+
+// parseInterval parses an interval from an EvaluationInterval. It returns a
+// zero duration if the interval is empty or "never".
+func parseInterval(interval string) (time.Duration, error) {
+	if interval == "" || interval == IntervalNever {
+		return 0, nil
+	}
+
+	dur, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("invalid evaluation interval %q: %w", interval, err)
+	}
+
+	if dur <= 0 {
+		return 0, fmt.Errorf("invalid evaluation interval %q: must be positive", interval)
+	}
+
+	return dur, nil
+}
+
+// RequeueAfter returns how long to wait before evaluating the policy again,
+// based on the EvaluationInterval in its spec, and the ComplianceState and
+// LastEvaluated time in its status. The result can be used directly as the
+// RequeueAfter in a controller-runtime Result: it is zero when the policy should
+// not be evaluated again periodically, and it is never negative.
+func RequeueAfter(policy PolicyTyper, now time.Time) (time.Duration, error) {
+	intervals := policy.PolicySpec().EvaluationInterval
+	status := policy.PolicyStatus()
+
+	interval := intervals.NonCompliant
+	if status.ComplianceState == Compliant {
+		interval = intervals.Compliant
+	}
+
+	dur, err := parseInterval(interval)
+	if err != nil || dur == 0 {
+		return 0, err
+	}
+
+	if status.LastEvaluated.IsZero() {
+		return minRequeueAfter, nil
+	}
+
+	remaining := status.LastEvaluated.Add(dur).Sub(now)
+	if remaining < minRequeueAfter {
+		return minRequeueAfter, nil
+	}
+
+	return remaining, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRequeueAfter(t *testing.T) {
+	type test struct {
+		name          string
+		intervals     EvaluationInterval
+		state         ComplianceState
+		lastEvaluated time.Duration // how long ago, or 0 for never
+		want          time.Duration
+		wantErr       bool
+	}
+
+	now := time.Now()
+
+	tests := []test{
+		{
+			name:          "unset",
+			state:         Compliant,
+			lastEvaluated: time.Second,
+			want:          0,
+		}, {
+			name:          "compliant just evaluated",
+			intervals:     EvaluationInterval{Compliant: "10m", NonCompliant: "30s"},
+			state:         Compliant,
+			lastEvaluated: time.Nanosecond,
+			want:          10*time.Minute - time.Nanosecond,
+		}, {
+			name:          "noncompliant partway through interval",
+			intervals:     EvaluationInterval{Compliant: "10m", NonCompliant: "30s"},
+			state:         NonCompliant,
+			lastEvaluated: 10 * time.Second,
+			want:          20 * time.Second,
+		}, {
+			name:          "unknown uses the noncompliant interval",
+			intervals:     EvaluationInterval{Compliant: "10m", NonCompliant: "1m"},
+			state:         UnknownCompliancy,
+			lastEvaluated: 30 * time.Second,
+			want:          30 * time.Second,
+		}, {
+			name:          "compliant never",
+			intervals:     EvaluationInterval{Compliant: "never", NonCompliant: "30s"},
+			state:         Compliant,
+			lastEvaluated: time.Second,
+			want:          0,
+		}, {
+			name:          "overdue",
+			intervals:     EvaluationInterval{NonCompliant: "30s"},
+			state:         NonCompliant,
+			lastEvaluated: time.Hour,
+			want:          minRequeueAfter,
+		}, {
+			name:      "never evaluated",
+			intervals: EvaluationInterval{NonCompliant: "30s"},
+			state:     NonCompliant,
+			want:      minRequeueAfter,
+		}, {
+			name:      "invalid interval",
+			intervals: EvaluationInterval{Compliant: "soon"},
+			state:     Compliant,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		policy := &PolicyType{
+			Spec:   PolicyTypeSpec{EvaluationInterval: tc.intervals},
+			Status: PolicyTypeStatus{ComplianceState: tc.state},
+		}
+		if tc.lastEvaluated != 0 {
+			policy.Status.LastEvaluated = metav1.NewTime(now.Add(-tc.lastEvaluated))
+		}
+
+		got, err := RequeueAfter(policy, now)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+		if got != tc.want {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
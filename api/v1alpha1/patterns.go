@@ -0,0 +1,143 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// RegexPatternPrefix marks a pattern in a NamespaceSelector as a regular
+// expression (using the RE2 syntax accepted by the Go regexp package) instead
+// of a UNIX style wildcard. The expression must match the whole namespace name.
+const RegexPatternPrefix string = "regex:"
+
+// NegatedPatternPrefix marks a pattern in a NamespaceSelector as an exception to
+// the patterns before it in the same list. It can be combined with the regex
+// prefix, for example "!regex:^openshift-(logging|monitoring)$".
+const NegatedPatternPrefix string = "!"
+
+// namespacePattern is a parsed pattern from a NamespaceSelector list.
+type namespacePattern struct {
+	negated bool
+	regex   *regexp.Regexp
+	glob    string
+}
+
+// parsePattern parses a single pattern from a NamespaceSelector list, and
+// returns an error if it is malformed. Since namespace names can not contain
+// "!" or ":", the prefixes do not change the meaning of any existing globs.
+func parsePattern(pattern string) (namespacePattern, error) {
+	parsed := namespacePattern{}
+
+	if strings.HasPrefix(pattern, NegatedPatternPrefix) {
+		parsed.negated = true
+		pattern = strings.TrimPrefix(pattern, NegatedPatternPrefix)
+	}
+
+	if strings.HasPrefix(pattern, RegexPatternPrefix) {
+		expr := strings.TrimPrefix(pattern, RegexPatternPrefix)
+
+		regex, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return parsed, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+
+		parsed.regex = regex
+
+		return parsed, nil
+	}
+
+	// filepath.Match only reports ErrBadPattern while it is matching, so check
+	// the pattern against an empty string to find any problems up front.
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return parsed, fmt.Errorf("invalid wildcard pattern %q: %w", pattern, err)
+	}
+
+	parsed.glob = pattern
+
+	return parsed, nil
+}
+
+// parsePatterns parses all of the patterns in a NamespaceSelector list.
+func parsePatterns(patterns []NonEmptyString) ([]namespacePattern, error) {
+	parsed := make([]namespacePattern, len(patterns))
+
+	for i, pattern := range patterns {
+		var err error
+
+		parsed[i], err = parsePattern(string(pattern))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parsed, nil
+}
+
+// matches returns true if the namespace matches the pattern, ignoring negation.
+func (p namespacePattern) matches(namespace string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(namespace)
+	}
+
+	// The pattern was already checked in parsePattern, so there can be no error
+	matched, _ := filepath.Match(p.glob, namespace)
+
+	return matched
+}
+
+// matchPatterns returns true if the namespace is selected by the list of
+// patterns. The patterns are checked in order, and the last one which matches
+// the namespace determines the result: a negated pattern un-selects the
+// namespace, and any other pattern selects it.
+func matchPatterns(patterns []namespacePattern, namespace string) bool {
+	selected := false
+
+	for _, pattern := range patterns {
+		if pattern.matches(namespace) {
+			selected = !pattern.negated
+		}
+	}
+
+	return selected
+}
+
+// ValidatePatterns checks that every pattern in the Include and Exclude lists
+// can be parsed, and returns an error for each one that can not. The path
+// should point to the NamespaceSelector in the policy.
+func (sel NamespaceSelector) ValidatePatterns(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, pattern := range sel.Include {
+		if _, err := parsePattern(string(pattern)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("include").Index(i), pattern, err.Error()))
+		}
+	}
+
+	for i, pattern := range sel.Exclude {
+		if _, err := parsePattern(string(pattern)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("exclude").Index(i), pattern, err.Error()))
+		}
+	}
+
+	return errs
+}
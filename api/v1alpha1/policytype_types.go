@@ -34,6 +34,28 @@ const (
 	UnknownCompliancy ComplianceState = "UnknownCompliancy"
 )
 
+// Severity is the canonical, lowercase form of the Severity field in a
+// PolicyTypeSpec. Use PolicyTypeSpec.GetSeverity to get it from a policy.
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// RemediationAction is the canonical, lowercase form of the RemediationAction
+// field in a PolicyTypeSpec. Use PolicyTypeSpec.GetRemediationAction to get it
+// from a policy.
+type RemediationAction string
+
+const (
+	RemediationInform  RemediationAction = "inform"
+	RemediationEnforce RemediationAction = "enforce"
+	RemediationDryRun  RemediationAction = "dryrun"
+)
+
 // PolicyTypeSpec includes all fields that should be implemented in the spec of
 // all policy types in the policy framework.
 type PolicyTypeSpec struct {
@@ -43,10 +65,12 @@ type PolicyTypeSpec struct {
 	Severity string `json:"severity,omitempty"`
 
 	// RemediationAction indicates what the policy controller should do when the
-	// policy is not compliant. Accepted values include inform, and enforce.
-	// Note that not all policy controllers will attempt to automatically
-	// remediate a policy, even when set to "enforce".
-	//+kubebuilder:validation:Enum=Inform;inform;Enforce;enforce
+	// policy is not compliant. Accepted values include inform, enforce, and
+	// dryrun. With dryrun, the controller reports what enforcing the policy
+	// would change, without changing anything. Note that not all policy
+	// controllers will attempt to automatically remediate a policy, even when
+	// set to "enforce".
+	//+kubebuilder:validation:Enum=Inform;inform;Enforce;enforce;DryRun;dryrun
 	RemediationAction string `json:"remediationAction,omitempty"`
 
 	// NamepaceSelector indicates which namespaces on the cluster this policy
@@ -58,20 +82,56 @@ type PolicyTypeSpec struct {
 	// policy should apply to. Not all policy controllers use this field, but
 	// if they do, the resources must match all labels specified here.
 	LabelSelector map[string]NonEmptyString `json:"labelSelector,omitempty"`
+
+	// EvaluationInterval configures how often the policy should be evaluated
+	// again after it has been evaluated, depending on its compliance. When it is
+	// not set, the policy is only evaluated again when something triggers it,
+	// like a change to the policy or to a namespace it selects.
+	EvaluationInterval EvaluationInterval `json:"evaluationInterval,omitempty"`
+}
+
+// EvaluationInterval configures how long to wait between evaluations of a
+// policy, with separate intervals for when it is compliant and not compliant.
+// Each interval is a duration like "30s", "10m", or "1h30m", or "never" to stop
+// evaluating the policy periodically while it has that compliance.
+type EvaluationInterval struct {
+	// Compliant is the interval to use when the policy is compliant.
+	//+kubebuilder:validation:Pattern=`^(?:never|(?:[0-9]+(?:\.[0-9]+)?(?:h|m|s|ms|us|ns))+)$`
+	Compliant string `json:"compliant,omitempty"`
+
+	// NonCompliant is the interval to use when the policy is not compliant, or
+	// when its compliance is unknown.
+	//+kubebuilder:validation:Pattern=`^(?:never|(?:[0-9]+(?:\.[0-9]+)?(?:h|m|s|ms|us|ns))+)$`
+	NonCompliant string `json:"noncompliant,omitempty"`
 }
 
 type NamespaceSelector struct {
 	// Include is a list of namespaces the policy should apply to. UNIX style
 	// wildcards will be expanded, for example "kube-*" will include both
-	// "kube-system" and "kube-public".
+	// "kube-system" and "kube-public". Items prefixed with "regex:" are instead
+	// regular expressions which must match the whole namespace name, and items
+	// prefixed with "!" are exceptions to the items before them in the list.
 	//+kubebuilder:validation:Required
 	//+kubebuilder:validation:MinItems=1
 	Include []NonEmptyString `json:"include,omitempty"`
 
 	// Exclude is a list of namespaces the policy should _not_ apply to. UNIX
 	// style wildcards will be expanded, for example "kube-*" will exclude both
-	// "kube-system" and "kube-public".
+	// "kube-system" and "kube-public". The "regex:" and "!" prefixes work the
+	// same as in Include, so ["openshift-*", "!openshift-logging"] excludes
+	// all of the "openshift-" namespaces except for "openshift-logging".
 	Exclude []NonEmptyString `json:"exclude,omitempty"`
+
+	// MatchLabels is a map of labels and values for the namespaces the policy
+	// should apply to. A namespace must have all of these labels, and must also
+	// be selected by the Include and Exclude lists.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// MatchExpressions is a list of label selector requirements for the
+	// namespaces the policy should apply to, using the same semantics as in a
+	// standard Kubernetes LabelSelector. A namespace must meet all of these
+	// requirements, and must also be selected by the Include and Exclude lists.
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
 }
 
 // PolicyTypeStatus includes fields that are useful for policy types in the
@@ -81,9 +141,10 @@ type PolicyTypeStatus struct {
 	// Accepted values include: Compliant, NonCompliant, and UnknownCompliancy
 	ComplianceState ComplianceState `json:"compliant,omitempty"`
 
-	// CompliancyDetails is implemented differently in each controller, in ways
-	// that are not compatible: configuration-policy uses a list, but iam-policy
-	// uses a map. So we can not include it in this definition.
+	// CompliancyDetails describes the compliance of each template in the
+	// policy, in a common format which can be rendered the same way for any
+	// policy type.
+	CompliancyDetails CompliancyDetails `json:"compliancyDetails,omitempty"`
 
 	// RelatedObjects are objects on the cluster that were examined in order to
 	// determine compliance. Often these are objects that cause a violation, but
@@ -92,12 +153,95 @@ type PolicyTypeStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// History is a list of the recent changes to the compliance of the policy,
+	// with the most recent first. It is trimmed to a limited length.
+	History []HistoryEntry `json:"history,omitempty"`
+
+	// LastEvaluated is when the policy was last evaluated. It is used with the
+	// EvaluationInterval in the spec to determine when to evaluate it again.
+	LastEvaluated metav1.Time `json:"lastEvaluated,omitempty"`
+
+	// ObservedGeneration is the generation of the policy that was evaluated to
+	// determine this status. When it does not match the generation of the
+	// policy, the status does not reflect the current spec.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// HistoryEntry records a change to the compliance of a policy.
+type HistoryEntry struct {
+	// Timestamp is when the change was observed.
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+
+	// ComplianceState is the compliance of the policy after the change.
+	ComplianceState ComplianceState `json:"compliant,omitempty"`
+
+	// Reason is the reason on the Compliant condition after the change.
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the message on the Compliant condition after the change.
+	Message string `json:"message,omitempty"`
+}
+
+// CompliancyDetails is a versioned list of the templates in a policy, with the
+// details of the compliance of each one.
+type CompliancyDetails struct {
+	// Version is the version of this format, so that consumers can tell how to
+	// render the details. The current version is "v1".
+	Version string `json:"version,omitempty"`
+
+	// Templates is the list of templates in the policy.
+	Templates []TemplateDetails `json:"templates,omitempty"`
+}
+
+// TemplateDetails describes the compliance of one template in a policy.
+type TemplateDetails struct {
+	// Name identifies the template within the policy.
+	Name string `json:"name,omitempty"`
+
+	// ComplianceState indicates whether the template is compliant or not.
+	ComplianceState ComplianceState `json:"compliant,omitempty"`
+
+	// Conditions are the observations which determined the compliance of the
+	// template, like the violations that were found.
+	Conditions []TemplateCondition `json:"conditions,omitempty"`
+
+	// History is the list of compliance changes of the template, with the most
+	// recent first.
+	History []ComplianceHistory `json:"history,omitempty"`
+}
+
+// TemplateCondition is an observation about a template. Unlike a
+// metav1.Condition, the Reason is free-form text, so that conditions from
+// existing policy controllers can be represented.
+type TemplateCondition struct {
+	Type               string                 `json:"type,omitempty"`
+	Status             metav1.ConditionStatus `json:"status,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// ComplianceHistory is a single compliance event in the history of a template.
+type ComplianceHistory struct {
+	// LastTimestamp is the last time the event was emitted.
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty"`
+
+	// Message is the full message of the event, including the compliance prefix.
+	Message string `json:"message,omitempty"`
+
+	// EventName is the name of the event object, which can be used to find it.
+	EventName string `json:"eventName,omitempty"`
 }
 
 type RelatedObject struct {
 	Object          ObjectRef       `json:"object,omitempty"`
 	ComplianceState ComplianceState `json:"compliant,omitempty"`
 	Reason          NonEmptyString  `json:"reason,omitempty"`
+
+	// Diff is a unified diff of the changes that enforcing the policy would make
+	// to the object, when the RemediationAction is dryrun.
+	Diff string `json:"diff,omitempty"`
 }
 
 type ObjectRef struct {
@@ -128,6 +272,9 @@ type PolicyType struct {
 	Status PolicyTypeStatus `json:"status,omitempty"`
 }
 
+// PolicyTyper is implemented by all policy types in the policy framework. The
+// PolicySpec and PolicyStatus methods should have pointer receivers, so that
+// changes made through the returned pointers are reflected in the object.
 //+kubebuilder:object:generate=false
 type PolicyTyper interface {
 	client.Object
@@ -135,11 +282,11 @@ type PolicyTyper interface {
 	PolicyStatus() *PolicyTypeStatus
 }
 
-func (p PolicyType) PolicySpec() *PolicyTypeSpec {
+func (p *PolicyType) PolicySpec() *PolicyTypeSpec {
 	return &p.Spec
 }
 
-func (p PolicyType) PolicyStatus() *PolicyTypeStatus {
+func (p *PolicyType) PolicyStatus() *PolicyTypeStatus {
 	return &p.Status
 }
 
@@ -154,3 +301,7 @@ type PolicyTypeList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []PolicyType `json:"items"`
 }
+
+func init() {
+	SchemeBuilder.Register(&PolicyType{}, &PolicyTypeList{})
+}
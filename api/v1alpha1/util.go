@@ -18,31 +18,81 @@ package v1alpha1
 
 import (
 	"context"
-	"path/filepath"
+	"errors"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var UnknownCompliancyMeansViolation = true
 
+// GetSeverity returns the Severity of the policy in its canonical lowercase
+// form, so that it can be compared with the Severity constants. If the field is
+// not set, it returns an empty Severity.
+func (spec PolicyTypeSpec) GetSeverity() Severity {
+	return Severity(strings.ToLower(spec.Severity))
+}
+
+// GetRemediationAction returns the RemediationAction of the policy in its
+// canonical lowercase form, so that it can be compared with the
+// RemediationAction constants. If the field is not set, it returns an empty
+// RemediationAction.
+func (spec PolicyTypeSpec) GetRemediationAction() RemediationAction {
+	return RemediationAction(strings.ToLower(spec.RemediationAction))
+}
+
+// IsEnforce returns true if the policy's RemediationAction is enforce, in any
+// casing.
+func (spec PolicyTypeSpec) IsEnforce() bool {
+	return spec.GetRemediationAction() == RemediationEnforce
+}
+
+// IsDryRun returns true if the policy's RemediationAction is dryrun, in any
+// casing.
+func (spec PolicyTypeSpec) IsDryRun() bool {
+	return spec.GetRemediationAction() == RemediationDryRun
+}
+
+// Default sets the Severity and RemediationAction of the policy to their
+// canonical lowercase forms, so that stored policies are consistent. Other
+// values are left unchanged, so that validation can still reject them.
+func (spec *PolicyTypeSpec) Default() {
+	if sev := spec.GetSeverity(); containsSeverity(sev) {
+		spec.Severity = string(sev)
+	}
+
+	if action := spec.GetRemediationAction(); containsRemediationAction(action) {
+		spec.RemediationAction = string(action)
+	}
+}
+
 // SortString returns a string which can help sort RelatedObjects.
 func (o RelatedObject) SortString() string {
 	return o.Object.APIVersion + o.Object.Kind + o.Object.Metadata.Namespace +
 		o.Object.Metadata.Name + string(o.Reason)
 }
 
-// GetNamespaces fetches all namespaces in the cluster and returns a list of the
-// namespaces that match the NamespaceSelector. The client.Reader needs access
-// for viewing namespaces, like the access given by this kubebuilder tag:
+// GetNamespaces fetches the namespaces in the cluster which match the label
+// requirements of the NamespaceSelector, and returns a list of the ones that
+// are also selected by its Include and Exclude lists. The client.Reader needs
+// access for viewing namespaces, like the access given by this kubebuilder tag:
 // `//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch`
 func (sel NamespaceSelector) GetNamespaces(ctx context.Context, r client.Reader) ([]string, error) {
 	matchingNamespaces := make([]string, 0)
 
+	labelSelector, err := sel.labelSelector()
+	if err != nil {
+		return matchingNamespaces, err
+	}
+
 	namespaceList := &corev1.NamespaceList{}
-	if err := r.List(ctx, namespaceList); err != nil {
+	err = r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: labelSelector})
+	if err != nil {
 		return matchingNamespaces, err
 	}
 
@@ -54,38 +104,54 @@ func (sel NamespaceSelector) GetNamespaces(ctx context.Context, r client.Reader)
 	return sel.matches(namespaces)
 }
 
+// Selects returns true if the given namespace is selected by the
+// NamespaceSelector, meaning that it meets the label requirements, and its name
+// is selected by the Include and Exclude lists.
+func (sel NamespaceSelector) Selects(namespace client.Object) (bool, error) {
+	labelSelector, err := sel.labelSelector()
+	if err != nil {
+		return false, err
+	}
+
+	if !labelSelector.Matches(labels.Set(namespace.GetLabels())) {
+		return false, nil
+	}
+
+	matched, err := sel.matches([]string{namespace.GetName()})
+
+	return len(matched) != 0, err
+}
+
+// labelSelector returns a labels.Selector built from the MatchLabels and
+// MatchExpressions in the NamespaceSelector. If neither are set, the returned
+// selector will match every namespace.
+func (sel NamespaceSelector) labelSelector() (labels.Selector, error) {
+	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      sel.MatchLabels,
+		MatchExpressions: sel.MatchExpressions,
+	})
+}
+
 // matches filters a slice of strings, and returns ones that match the selector
 func (sel NamespaceSelector) matches(namespaces []string) ([]string, error) {
 	matchingNamespaces := make([]string, 0)
 
+	includePatterns, err := parsePatterns(sel.Include)
+	if err != nil {
+		return matchingNamespaces, err
+	}
+
+	excludePatterns, err := parsePatterns(sel.Exclude)
+	if err != nil {
+		return matchingNamespaces, err
+	}
+
 	for _, namespace := range namespaces {
-		include := false
-		for _, includePattern := range sel.Include {
-			var err error
-			include, err = filepath.Match(string(includePattern), namespace)
-			if err != nil { // The only possible returned error is ErrBadPattern, when pattern is malformed.
-				return matchingNamespaces, err
-			}
-			if include {
-				break
-			}
-		}
-		if !include {
+		if !matchPatterns(includePatterns, namespace) {
 			continue
 		}
 
-		exclude := false
-		for _, excludePattern := range sel.Exclude {
-			var err error
-			exclude, err = filepath.Match(string(excludePattern), namespace)
-			if err != nil { // The only possible returned error is ErrBadPattern, when pattern is malformed.
-				return matchingNamespaces, err
-			}
-			if exclude {
-				break
-			}
-		}
-		if exclude {
+		if matchPatterns(excludePatterns, namespace) {
 			continue
 		}
 
@@ -95,18 +161,107 @@ func (sel NamespaceSelector) matches(namespaces []string) ([]string, error) {
 	return matchingNamespaces, nil
 }
 
-// RecordComplianceEvent creates an event on the "parent" policy of the given
-// object (found through ownerReferences, which is set by the policy framework)
-// which can be recognized by the policy framework to update the parent policy's
-// status. This is the way that compliance information gets sent to the hub.
-// The provided message will be prepended with "Compliant; " or "NonCompliant; "
-// as required by the policy framework. The record.EventRecorder needs access to
-// create and update events, like the access given by this kubebuilder tag:
+// ParentPolicyKinds are the kinds of owners which RecordComplianceEvent will
+// send compliance events to. By default, only the policy framework's Policy
+// kind is used, so that other owners (for example from a GitOps tool) do not
+// receive the events. If it is empty, every owner is treated as a parent. Use
+// RegisterParentPolicyKind to add a kind, rather than appending to it.
+var ParentPolicyKinds = []schema.GroupKind{{Group: GroupVersion.Group, Kind: "Policy"}}
+
+// RegisterParentPolicyKind adds the kind to ParentPolicyKinds, unless it is
+// already there, so it can be called more than once for the same kind, for
+// example by both a controller's main function and its tests. It is not safe to
+// call while events are being recorded, so it should be called during setup.
+func RegisterParentPolicyKind(gk schema.GroupKind) {
+	for _, existing := range ParentPolicyKinds {
+		if existing == gk {
+			return
+		}
+	}
+
+	ParentPolicyKinds = append(ParentPolicyKinds, gk)
+}
+
+// ErrNoParentPolicy is returned by RecordComplianceEvent when the policy does
+// not have any owners matching ParentPolicyKinds, so no event was created.
+var ErrNoParentPolicy = errors.New("no parent policy found in ownerReferences")
+
+// RecordComplianceEvent creates an event on each "parent" policy of the given
+// object (found through ownerReferences which match ParentPolicyKinds, which is
+// set by the policy framework) which can be recognized by the policy framework
+// to update the parent policy's status. This is the way that compliance
+// information gets sent to the hub. The provided message will be prepended
+// with "Compliant; " or "NonCompliant; " as required by the policy framework.
+// If no parent is found, ErrNoParentPolicy is returned. The
+// record.EventRecorder needs access to create and update events, like the
+// access given by this kubebuilder tag:
 // `//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch`
-func RecordComplianceEvent(r record.EventRecorder, policy PolicyTyper, msg string) {
-	if len(policy.GetOwnerReferences()) != 0 {
-		ownerRef := policy.GetOwnerReferences()[0]
-		parentPolicy := &PolicyType{
+func RecordComplianceEvent(r record.EventRecorder, policy PolicyTyper, msg string) error {
+	return recordComplianceEvent(r, policy, msg, nil)
+}
+
+// recordComplianceEvent implements RecordComplianceEvent, and also adds the
+// annotations to the events if any are provided.
+func recordComplianceEvent(
+	r record.EventRecorder, policy PolicyTyper, msg string, annotations map[string]string,
+) error {
+	parents := parentPolicies(policy)
+	if len(parents) == 0 {
+		return ErrNoParentPolicy
+	}
+
+	var eventType, msgPrefix string
+	switch policy.PolicyStatus().ComplianceState {
+	case Compliant:
+		eventType = "Normal"
+		msgPrefix = compliantPrefix
+	case NonCompliant:
+		eventType = "Warning"
+		msgPrefix = nonCompliantPrefix
+	default:
+		if UnknownCompliancyMeansViolation {
+			eventType = "Warning"
+			msgPrefix = nonCompliantPrefix
+		} else {
+			eventType = "Normal"
+			msgPrefix = compliantPrefix
+		}
+	}
+
+	reason := eventReasonPrefix + policy.GetNamespace() + "/" + policy.GetName()
+
+	for _, parentPolicy := range parents {
+		if len(annotations) == 0 {
+			r.Event(parentPolicy, eventType, reason, msgPrefix+msg)
+		} else {
+			r.AnnotatedEventf(parentPolicy, annotations, eventType, reason, "%s", msgPrefix+msg)
+		}
+	}
+
+	return nil
+}
+
+// ParentPolicyOwners returns the owner references of the object which match
+// ParentPolicyKinds, which are the parents that compliance events are sent to.
+func ParentPolicyOwners(obj metav1.Object) []metav1.OwnerReference {
+	owners := make([]metav1.OwnerReference, 0)
+
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if isParentKind(ownerRef) {
+			owners = append(owners, ownerRef)
+		}
+	}
+
+	return owners
+}
+
+// parentPolicies returns objects representing each owner of the policy which
+// matches ParentPolicyKinds, suitable for use as the object of an event.
+func parentPolicies(policy PolicyTyper) []*PolicyType {
+	parents := make([]*PolicyType, 0)
+
+	for _, ownerRef := range ParentPolicyOwners(policy) {
+		parents = append(parents, &PolicyType{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      ownerRef.Name,
 				Namespace: policy.GetNamespace(), // K8s ensures that owning objects are in the same namespace
@@ -116,28 +271,29 @@ func RecordComplianceEvent(r record.EventRecorder, policy PolicyTyper, msg strin
 				Kind:       ownerRef.Kind,
 				APIVersion: ownerRef.APIVersion,
 			},
-		}
+		})
+	}
 
-		var eventType, msgPrefix string
-		switch policy.PolicyStatus().ComplianceState {
-		case Compliant:
-			eventType = "Normal"
-			msgPrefix = "Compliant; "
-		case NonCompliant:
-			eventType = "Warning"
-			msgPrefix = "NonCompliant; "
-		default:
-			if UnknownCompliancyMeansViolation {
-				eventType = "Warning"
-				msgPrefix = "NonCompliant; "
-			} else {
-				eventType = "Normal"
-				msgPrefix = "Compliant; "
-			}
-		}
+	return parents
+}
 
-		reason := "policy: " + policy.GetNamespace() + "/" + policy.GetName()
+// isParentKind returns true if the owner's group and kind are in
+// ParentPolicyKinds, or if ParentPolicyKinds is empty.
+func isParentKind(ownerRef metav1.OwnerReference) bool {
+	if len(ParentPolicyKinds) == 0 {
+		return true
+	}
 
-		r.Event(parentPolicy, eventType, reason, msgPrefix+msg)
+	gv, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+	if err != nil {
+		return false
 	}
+
+	for _, gk := range ParentPolicyKinds {
+		if gk.Group == gv.Group && gk.Kind == ownerRef.Kind {
+			return true
+		}
+	}
+
+	return false
 }
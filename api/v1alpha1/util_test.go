@@ -16,7 +16,20 @@ limitations under the License.
 
 package v1alpha1
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
 
 func TestMatches(t *testing.T) {
 	type test struct {
@@ -76,6 +89,30 @@ func TestMatches(t *testing.T) {
 			name:    "include ??? (three letters only), exclude foo",
 			sel:     NamespaceSelector{Include: []NonEmptyString{"???"}, Exclude: []NonEmptyString{"foo"}},
 			wantLen: 3,
+		}, {
+			name:    "include regex ending in a letter other than o",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"regex:.*[a-np-z]"}, Exclude: []NonEmptyString{}},
+			wantLen: 5,
+		}, {
+			name:    "include regex is anchored",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"regex:kube"}, Exclude: []NonEmptyString{}},
+			wantLen: 0,
+		}, {
+			name:    "include regex alternation",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"regex:kube-(one|two)|foo"}, Exclude: []NonEmptyString{}},
+			wantLen: 3,
+		}, {
+			name:    "exclude kube*, except kube-two",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"*"}, Exclude: []NonEmptyString{"kube*", "!kube-two"}},
+			wantLen: 6,
+		}, {
+			name:    "include kube*, except by regex, then re-include kube-three",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"kube-*", "!regex:kube-t.*", "kube-three"}, Exclude: []NonEmptyString{}},
+			wantLen: 2,
+		}, {
+			name:    "negation before a match has no effect",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"!foo", "*"}, Exclude: []NonEmptyString{}},
+			wantLen: len(testInput),
 		},
 	}
 
@@ -89,3 +126,222 @@ func TestMatches(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchesInvalidPatterns(t *testing.T) {
+	tests := map[string]NamespaceSelector{
+		"bad glob in include":          {Include: []NonEmptyString{"kube-[a"}},
+		"bad glob in exclude":          {Include: []NonEmptyString{"*"}, Exclude: []NonEmptyString{"[]a]"}},
+		"bad regex in include":         {Include: []NonEmptyString{"regex:kube-(a"}},
+		"bad negated regex in exclude": {Include: []NonEmptyString{"*"}, Exclude: []NonEmptyString{"!regex:*"}},
+	}
+
+	for name, sel := range tests {
+		if _, err := sel.matches([]string{"default"}); err == nil {
+			t.Errorf("test '%v' expected an error from matches", name)
+		}
+		if errs := sel.ValidatePatterns(field.NewPath("namespaceSelector")); len(errs) != 1 {
+			t.Errorf("test '%v' expected 1 error from ValidatePatterns, got: %v", name, errs)
+		}
+	}
+}
+
+func TestGetNamespacesLabels(t *testing.T) {
+	type test struct {
+		name    string
+		sel     NamespaceSelector
+		wantLen int
+		wantErr bool
+	}
+
+	testNamespaces := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-payments", Labels: map[string]string{"env": "prod", "team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod-search", Labels: map[string]string{"env": "prod", "team": "search"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev-payments", Labels: map[string]string{"env": "dev", "team": "payments"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	}
+
+	tests := []test{
+		{
+			name:    "no label requirements",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"*"}},
+			wantLen: 4,
+		}, {
+			name:    "matchLabels env=prod",
+			sel:     NamespaceSelector{Include: []NonEmptyString{"*"}, MatchLabels: map[string]string{"env": "prod"}},
+			wantLen: 2,
+		}, {
+			name: "matchLabels and matchExpressions",
+			sel: NamespaceSelector{
+				Include:     []NonEmptyString{"*"},
+				MatchLabels: map[string]string{"team": "payments"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "env",
+					Operator: metav1.LabelSelectorOpNotIn,
+					Values:   []string{"dev"},
+				}},
+			},
+			wantLen: 1,
+		}, {
+			name: "matchExpressions Exists",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"*"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "team",
+					Operator: metav1.LabelSelectorOpExists,
+				}},
+			},
+			wantLen: 3,
+		}, {
+			name: "labels combined with include and exclude",
+			sel: NamespaceSelector{
+				Include:     []NonEmptyString{"prod-*"},
+				Exclude:     []NonEmptyString{"*-search"},
+				MatchLabels: map[string]string{"env": "prod"},
+			},
+			wantLen: 1,
+		}, {
+			name: "invalid operator",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"*"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "env",
+					Operator: "Matches",
+				}},
+			},
+			wantErr: true,
+		},
+	}
+
+	r := fake.NewClientBuilder().WithObjects(testNamespaces...).Build()
+
+	for _, tc := range tests {
+		got, err := tc.sel.GetNamespaces(context.TODO(), r)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+		if len(got) != tc.wantLen {
+			t.Errorf("test '%v' expected len: %v, got: %v, matches: %v", tc.name, tc.wantLen, len(got), got)
+		}
+	}
+}
+
+func TestSpecHelpers(t *testing.T) {
+	spec := PolicyTypeSpec{Severity: "High", RemediationAction: "Enforce"}
+
+	if spec.GetSeverity() != SeverityHigh {
+		t.Errorf("expected severity: %v, got: %v", SeverityHigh, spec.GetSeverity())
+	}
+	if spec.GetRemediationAction() != RemediationEnforce {
+		t.Errorf("expected remediationAction: %v, got: %v", RemediationEnforce, spec.GetRemediationAction())
+	}
+	if !spec.IsEnforce() {
+		t.Error("expected IsEnforce to be true")
+	}
+
+	if spec.IsDryRun() {
+		t.Error("expected IsDryRun to be false")
+	}
+
+	spec.Default()
+	if spec.Severity != "high" || spec.RemediationAction != "enforce" {
+		t.Errorf("expected canonical values after Default, got: %v, %v", spec.Severity, spec.RemediationAction)
+	}
+
+	spec.RemediationAction = "DryRun"
+	spec.Default()
+	if !spec.IsDryRun() || spec.RemediationAction != "dryrun" {
+		t.Errorf("expected a canonical dryrun after Default, got: %v", spec.RemediationAction)
+	}
+}
+
+func TestRecordComplianceEvent(t *testing.T) {
+	type test struct {
+		name       string
+		owners     []metav1.OwnerReference
+		wantErr    error
+		wantEvents []string
+	}
+
+	policyOwner := func(name string) metav1.OwnerReference {
+		return metav1.OwnerReference{APIVersion: "policy.open-cluster-management.io/v1", Kind: "Policy", Name: name}
+	}
+	gitopsOwner := metav1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Application", Name: "app"}
+
+	tests := []test{
+		{
+			name:    "no owners",
+			wantErr: ErrNoParentPolicy,
+		}, {
+			name:    "only a non-policy owner",
+			owners:  []metav1.OwnerReference{gitopsOwner},
+			wantErr: ErrNoParentPolicy,
+		}, {
+			name:       "non-policy owner first",
+			owners:     []metav1.OwnerReference{gitopsOwner, policyOwner("parent")},
+			wantEvents: []string{"Normal policy: default/test Compliant; ok"},
+		}, {
+			name:   "multiple parents",
+			owners: []metav1.OwnerReference{policyOwner("one"), policyOwner("two")},
+			wantEvents: []string{
+				"Normal policy: default/test Compliant; ok",
+				"Normal policy: default/test Compliant; ok",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		policy := &PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", OwnerReferences: tc.owners},
+			Status:     PolicyTypeStatus{ComplianceState: Compliant},
+		}
+
+		recorder := record.NewFakeRecorder(len(tc.owners))
+
+		if err := RecordComplianceEvent(recorder, policy, "ok"); !errors.Is(err, tc.wantErr) {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		close(recorder.Events)
+
+		got := make([]string, 0)
+		for event := range recorder.Events {
+			got = append(got, event)
+		}
+
+		if len(got) != len(tc.wantEvents) {
+			t.Errorf("test '%v' expected events: %v, got: %v", tc.name, tc.wantEvents, got)
+			continue
+		}
+
+		for i := range got {
+			if got[i] != tc.wantEvents[i] {
+				t.Errorf("test '%v' expected event: %v, got: %v", tc.name, tc.wantEvents[i], got[i])
+			}
+		}
+	}
+}
+
+func TestRegisterParentPolicyKind(t *testing.T) {
+	defaultKinds := ParentPolicyKinds
+	defer func() { ParentPolicyKinds = defaultKinds }()
+
+	ParentPolicyKinds = append([]schema.GroupKind{}, defaultKinds...)
+
+	mockKind := schema.GroupKind{Group: GroupVersion.Group, Kind: "MockPolicy"}
+
+	RegisterParentPolicyKind(mockKind)
+	RegisterParentPolicyKind(mockKind)
+	RegisterParentPolicyKind(defaultKinds[0])
+
+	want := []schema.GroupKind{defaultKinds[0], mockKind}
+	if !reflect.DeepEqual(ParentPolicyKinds, want) {
+		t.Errorf("expected: %v, got: %v", want, ParentPolicyKinds)
+	}
+
+	mockOwner := metav1.OwnerReference{APIVersion: "policy.open-cluster-management.io/v1", Kind: "MockPolicy", Name: "p"}
+	policy := &PolicyType{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{mockOwner}}}
+
+	if owners := ParentPolicyOwners(policy); len(owners) != 1 {
+		t.Errorf("expected the MockPolicy owner to be a parent, got: %v", owners)
+	}
+}
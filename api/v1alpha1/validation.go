@@ -0,0 +1,164 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// validSeverities are the accepted values for Severity, ignoring casing.
+var validSeverities = []Severity{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// validRemediationActions are the accepted values for RemediationAction,
+// ignoring casing.
+var validRemediationActions = []RemediationAction{RemediationInform, RemediationEnforce, RemediationDryRun}
+
+// Validate checks the PolicyTypeSpec for problems that can not be expressed in
+// the CRD schema, like malformed namespace patterns, and returns all of them.
+// The path should point to the PolicyTypeSpec fields in the policy, which is
+// usually just `field.NewPath("spec")` since the fields are inlined.
+func (spec PolicyTypeSpec) Validate(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if spec.Severity != "" && !containsSeverity(spec.GetSeverity()) {
+		supported := make([]string, len(validSeverities))
+		for i, sev := range validSeverities {
+			supported[i] = string(sev)
+		}
+
+		errs = append(errs, field.NotSupported(path.Child("severity"), spec.Severity, supported))
+	}
+
+	if spec.RemediationAction != "" && !containsRemediationAction(spec.GetRemediationAction()) {
+		supported := make([]string, len(validRemediationActions))
+		for i, action := range validRemediationActions {
+			supported[i] = string(action)
+		}
+
+		errs = append(errs, field.NotSupported(path.Child("remediationAction"), spec.RemediationAction, supported))
+	}
+
+	errs = append(errs, spec.NamespaceSelector.Validate(path.Child("namespaceSelector"))...)
+
+	intervalPath := path.Child("evaluationInterval")
+	if _, err := parseInterval(spec.EvaluationInterval.Compliant); err != nil {
+		errs = append(errs, field.Invalid(intervalPath.Child("compliant"), spec.EvaluationInterval.Compliant, err.Error()))
+	}
+
+	if _, err := parseInterval(spec.EvaluationInterval.NonCompliant); err != nil {
+		errs = append(errs, field.Invalid(
+			intervalPath.Child("noncompliant"), spec.EvaluationInterval.NonCompliant, err.Error()))
+	}
+
+	labelPath := path.Child("labelSelector")
+	for key, val := range spec.LabelSelector {
+		errs = append(errs, metav1validation.ValidateLabelName(key, labelPath)...)
+
+		if val == "" {
+			errs = append(errs, field.Required(labelPath.Key(key), "label values must not be empty"))
+		}
+	}
+
+	return errs
+}
+
+// RemediationActionSupporter can be implemented by policy types which do not
+// support every RemediationAction, for example types whose controllers only
+// report compliance and never remediate. ValidatePolicy rejects policies of
+// these types which use any other RemediationAction.
+//+kubebuilder:object:generate=false
+type RemediationActionSupporter interface {
+	SupportedRemediationActions() []RemediationAction
+}
+
+// ValidatePolicy checks the PolicyTypeSpec of the policy with Validate. If the
+// policy type implements RemediationActionSupporter, it also checks that the
+// RemediationAction is supported by the type.
+func ValidatePolicy(policy PolicyTyper) field.ErrorList {
+	path := field.NewPath("spec")
+	spec := policy.PolicySpec()
+	errs := spec.Validate(path)
+
+	if supporter, ok := policy.(RemediationActionSupporter); ok {
+		errs = append(errs, spec.ValidateRemediationAction(path, supporter.SupportedRemediationActions())...)
+	}
+
+	return errs
+}
+
+// ValidateRemediationAction returns an error on the RemediationAction if it is
+// set to an accepted value which is not one of the supported actions, ignoring
+// casing. Values which are not accepted at all are reported by Validate.
+func (spec PolicyTypeSpec) ValidateRemediationAction(
+	path *field.Path, supported []RemediationAction,
+) field.ErrorList {
+	action := spec.GetRemediationAction()
+	if action == "" || !containsRemediationAction(action) {
+		return field.ErrorList{}
+	}
+
+	for _, valid := range supported {
+		if action == valid {
+			return field.ErrorList{}
+		}
+	}
+
+	return field.ErrorList{field.Invalid(path.Child("remediationAction"), spec.RemediationAction,
+		fmt.Sprintf("this policy type only supports these remediation actions: %v", supported))}
+}
+
+// Validate checks that every pattern in the Include and Exclude lists can be
+// parsed, and that the label requirements are valid, and returns all problems.
+func (sel NamespaceSelector) Validate(path *field.Path) field.ErrorList {
+	errs := sel.ValidatePatterns(path)
+
+	// The label requirements are checked the same way as a standard Kubernetes
+	// LabelSelector, but the fields are directly in the NamespaceSelector.
+	errs = append(errs, metav1validation.ValidateLabelSelector(&metav1.LabelSelector{
+		MatchLabels:      sel.MatchLabels,
+		MatchExpressions: sel.MatchExpressions,
+	}, path)...)
+
+	return errs
+}
+
+// containsSeverity returns true if the Severity is one of the accepted values.
+func containsSeverity(sev Severity) bool {
+	for _, valid := range validSeverities {
+		if sev == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsRemediationAction returns true if the RemediationAction is one of the
+// accepted values.
+func containsRemediationAction(action RemediationAction) bool {
+	for _, valid := range validRemediationActions {
+		if action == valid {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// informOnlyPolicy is a policy type which does not support remediation.
+type informOnlyPolicy struct {
+	PolicyType
+}
+
+func (p *informOnlyPolicy) SupportedRemediationActions() []RemediationAction {
+	return []RemediationAction{RemediationInform}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	type test struct {
+		name      string
+		policy    PolicyTyper
+		wantField string
+	}
+
+	selector := NamespaceSelector{Include: []NonEmptyString{"*"}}
+	spec := func(action string) PolicyTypeSpec {
+		return PolicyTypeSpec{Severity: "high", RemediationAction: action, NamespaceSelector: selector}
+	}
+
+	tests := []test{
+		{
+			name:   "enforce on a type which supports every action",
+			policy: &PolicyType{Spec: spec("enforce")},
+		}, {
+			name:   "inform on an inform-only type",
+			policy: &informOnlyPolicy{PolicyType{Spec: spec("Inform")}},
+		}, {
+			name:   "unset action on an inform-only type",
+			policy: &informOnlyPolicy{PolicyType{Spec: spec("")}},
+		}, {
+			name:      "enforce on an inform-only type",
+			policy:    &informOnlyPolicy{PolicyType{Spec: spec("Enforce")}},
+			wantField: "spec.remediationAction",
+		}, {
+			name:      "dryrun on an inform-only type",
+			policy:    &informOnlyPolicy{PolicyType{Spec: spec("dryrun")}},
+			wantField: "spec.remediationAction",
+		}, {
+			// The unsupported value is only reported once, by Validate
+			name:      "unknown action on an inform-only type",
+			policy:    &informOnlyPolicy{PolicyType{Spec: spec("fix")}},
+			wantField: "spec.remediationAction",
+		},
+	}
+
+	for _, tc := range tests {
+		errs := ValidatePolicy(tc.policy)
+
+		if tc.wantField == "" {
+			if len(errs) != 0 {
+				t.Errorf("test '%v' expected no errors, got: %v", tc.name, errs)
+			}
+
+			continue
+		}
+
+		if len(errs) != 1 || errs[0].Field != tc.wantField {
+			t.Errorf("test '%v' expected: an error on %v, got: %v", tc.name, tc.wantField, errs)
+		}
+	}
+}
+
+func TestValidateRemediationAction(t *testing.T) {
+	supported := []RemediationAction{RemediationInform, RemediationDryRun}
+	path := field.NewPath("spec")
+
+	type test struct {
+		action  string
+		wantErr bool
+	}
+
+	tests := []test{
+		{action: "", wantErr: false},
+		{action: "inform", wantErr: false},
+		{action: "DryRun", wantErr: false},
+		{action: "enforce", wantErr: true},
+		{action: "Enforce", wantErr: true},
+		{action: "bogus", wantErr: false},
+	}
+
+	for _, tc := range tests {
+		errs := PolicyTypeSpec{RemediationAction: tc.action}.ValidateRemediationAction(path, supported)
+		if got := len(errs) != 0; got != tc.wantErr {
+			t.Errorf("test '%v' expected an error: %v, got: %v", tc.action, tc.wantErr, errs)
+		}
+
+		if len(errs) != 0 && errs[0].Type != field.ErrorTypeInvalid {
+			t.Errorf("test '%v' expected an Invalid error, got: %v", tc.action, errs[0].Type)
+		}
+	}
+}
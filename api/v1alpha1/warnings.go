@@ -0,0 +1,276 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Warnings checks the PolicyTypeSpec for problems which do not make it
+// invalid, but which are probably mistakes, like namespace patterns which can
+// never select anything. Problems reported by Validate are not repeated here.
+func (spec PolicyTypeSpec) Warnings(path *field.Path) field.ErrorList {
+	return spec.NamespaceSelector.Warnings(path.Child("namespaceSelector"))
+}
+
+// Warnings checks the NamespaceSelector for Include patterns which can never
+// select a namespace, either because they are not valid namespace names or
+// because they are shadowed by an Exclude pattern, and for label requirements
+// which no namespace can meet. Only cases that are certain are reported, so
+// for example a regular expression is only considered shadowed by an Exclude
+// pattern of "*".
+func (sel NamespaceSelector) Warnings(path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	includes, err := parsePatterns(sel.Include)
+	if err != nil {
+		// Validate reports the malformed pattern
+		return errs
+	}
+
+	excludes, err := parsePatterns(sel.Exclude)
+	if err != nil {
+		return errs
+	}
+
+	positive := 0
+
+	for i, include := range includes {
+		if include.negated {
+			continue
+		}
+
+		positive++
+
+		includePath := path.Child("include").Index(i)
+
+		if include.isLiteral() && len(validation.IsDNS1123Label(include.glob)) != 0 {
+			errs = append(errs, field.Invalid(includePath, sel.Include[i],
+				"this is not a valid namespace name, so it can never select a namespace"))
+
+			continue
+		}
+
+		if j := shadowingExclude(include, excludes); j != -1 {
+			errs = append(errs, field.Invalid(includePath, sel.Include[i],
+				fmt.Sprintf("this is shadowed by the exclude pattern %q, so it can never select a namespace",
+					sel.Exclude[j])))
+		}
+	}
+
+	if positive == 0 && len(sel.Include) != 0 {
+		errs = append(errs, field.Invalid(path.Child("include"), sel.Include,
+			"every pattern is negated, so no namespace can be selected"))
+	}
+
+	for _, key := range sel.unsatisfiableLabels() {
+		errs = append(errs, field.Invalid(path, key,
+			fmt.Sprintf("no namespace can meet the label requirements for %q", key)))
+	}
+
+	return errs
+}
+
+// isLiteral returns true if the pattern is a plain name, which only matches
+// the namespace with that exact name.
+func (p namespacePattern) isLiteral() bool {
+	return p.regex == nil && !strings.ContainsAny(p.glob, `*?[\`)
+}
+
+// shadowingExclude returns the index of an Exclude pattern which excludes every
+// namespace that the include pattern matches, or -1 if there is none.
+func shadowingExclude(include namespacePattern, excludes []namespacePattern) int {
+	if include.isLiteral() {
+		// The exact result is known, including any exceptions in the excludes
+		if !matchPatterns(excludes, include.glob) {
+			return -1
+		}
+
+		for j := len(excludes) - 1; j >= 0; j-- {
+			if !excludes[j].negated && excludes[j].matches(include.glob) {
+				return j
+			}
+		}
+
+		return -1
+	}
+
+	for j, exclude := range excludes {
+		if exclude.negated || !exclude.covers(include) {
+			continue
+		}
+
+		// A later exception might select some of the namespaces again
+		if !anyNegated(excludes[j+1:]) {
+			return j
+		}
+	}
+
+	return -1
+}
+
+// covers returns true if the pattern matches every namespace that the other
+// pattern matches. It only handles the cases which can be decided exactly, and
+// returns false otherwise.
+func (p namespacePattern) covers(other namespacePattern) bool {
+	if p.regex != nil {
+		return other.regex != nil && p.regex.String() == other.regex.String()
+	}
+
+	// A glob whose only wildcard is "*" matches the skeleton of the other glob
+	// as a string exactly when each wildcard in the other glob lines up with a
+	// "*" in this one, which means that it matches everything the other glob
+	// matches.
+	if strings.ContainsAny(p.glob, `?[\`) {
+		return other.regex == nil && p.glob == other.glob
+	}
+
+	if other.regex != nil {
+		return strings.Trim(p.glob, "*") == ""
+	}
+
+	matched, _ := filepath.Match(p.glob, globSkeleton(other.glob))
+
+	return matched
+}
+
+// wildcardPlaceholder stands in for a single character wildcard in a glob
+// skeleton. It can not be matched by anything but a "*" in a glob, since it is
+// not a valid character in a namespace name.
+const wildcardPlaceholder = "\x00"
+
+// globSkeleton returns the glob with each "?" and character class, like
+// "[a-z]", replaced by the wildcardPlaceholder, and with escaped characters
+// unescaped. Any "*" is kept, and a "*" in a glob only matches it with another
+// "*", so the skeleton lets filepath.Match compare globs without treating the
+// other wildcards as literal characters. The glob must already be valid.
+func globSkeleton(glob string) string {
+	var skeleton strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+			skeleton.WriteByte(glob[i])
+		case '?':
+			skeleton.WriteString(wildcardPlaceholder)
+		case '[':
+			i++
+			if glob[i] == '^' {
+				i++
+			}
+
+			for glob[i] != ']' {
+				if glob[i] == '\\' {
+					i++
+				}
+				i++
+			}
+
+			skeleton.WriteString(wildcardPlaceholder)
+		default:
+			skeleton.WriteByte(glob[i])
+		}
+	}
+
+	return skeleton.String()
+}
+
+// anyNegated returns true if any of the patterns are negated.
+func anyNegated(patterns []namespacePattern) bool {
+	for _, p := range patterns {
+		if p.negated {
+			return true
+		}
+	}
+
+	return false
+}
+
+// labelRequirement collects the requirements on a single label from the
+// MatchLabels and MatchExpressions of a NamespaceSelector.
+type labelRequirement struct {
+	mustExist    bool
+	mustNotExist bool
+	// allowed is nil when any value is allowed
+	allowed   sets.String
+	forbidden sets.String
+}
+
+// unsatisfiableLabels returns the sorted keys of the labels whose requirements
+// contradict each other, so that no namespace can meet them.
+func (sel NamespaceSelector) unsatisfiableLabels() []string {
+	reqs := make(map[string]*labelRequirement)
+	get := func(key string) *labelRequirement {
+		if _, found := reqs[key]; !found {
+			reqs[key] = &labelRequirement{forbidden: sets.NewString()}
+		}
+
+		return reqs[key]
+	}
+
+	allow := func(req *labelRequirement, values ...string) {
+		req.mustExist = true
+
+		if req.allowed == nil {
+			req.allowed = sets.NewString(values...)
+		} else {
+			req.allowed = req.allowed.Intersection(sets.NewString(values...))
+		}
+	}
+
+	for key, val := range sel.MatchLabels {
+		allow(get(key), val)
+	}
+
+	for _, expr := range sel.MatchExpressions {
+		req := get(expr.Key)
+
+		switch expr.Operator {
+		case metav1.LabelSelectorOpIn:
+			allow(req, expr.Values...)
+		case metav1.LabelSelectorOpNotIn:
+			req.forbidden.Insert(expr.Values...)
+		case metav1.LabelSelectorOpExists:
+			req.mustExist = true
+		case metav1.LabelSelectorOpDoesNotExist:
+			req.mustNotExist = true
+		}
+	}
+
+	keys := make([]string, 0)
+
+	for key, req := range reqs {
+		if req.mustExist && req.mustNotExist {
+			keys = append(keys, key)
+		} else if req.allowed != nil && req.allowed.Difference(req.forbidden).Len() == 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
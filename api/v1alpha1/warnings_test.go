@@ -0,0 +1,160 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNamespaceSelectorWarnings(t *testing.T) {
+	type test struct {
+		name       string
+		sel        NamespaceSelector
+		wantFields []string
+	}
+
+	tests := []test{
+		{
+			name: "no problems",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"*"},
+				Exclude: []NonEmptyString{"kube-*", "openshift-*", "!openshift-logging"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "literal excluded by glob",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"default", "kube-system"},
+				Exclude: []NonEmptyString{"kube-*"},
+			},
+			wantFields: []string{"sel.include[1]"},
+		}, {
+			name: "literal excluded then re-included",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"kube-system"},
+				Exclude: []NonEmptyString{"kube-*", "!kube-system"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "glob covered by a wider glob",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"kube-*", "app-*"},
+				Exclude: []NonEmptyString{"kube*"},
+			},
+			wantFields: []string{"sel.include[0]"},
+		}, {
+			name: "glob not covered by a narrower glob",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"kube-*"},
+				Exclude: []NonEmptyString{"kube-s*", "kube-?"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "glob with wildcards covered by a wider glob",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"ns-[ab]x", "ns-?y", `ns-[\]a]z`},
+				Exclude: []NonEmptyString{"ns-*"},
+			},
+			wantFields: []string{"sel.include[0]", "sel.include[1]", "sel.include[2]"},
+		}, {
+			name: "character classes are not literal text",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"ns-a", "ns-[ab]", "app-?"},
+				Exclude: []NonEmptyString{"ns-[ab]x", "ns-*b]", "app-[?]"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "glob covered, but with a later exception",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"kube-*"},
+				Exclude: []NonEmptyString{"*", "!kube-public"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "regex covered by a star",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"regex:app-[0-9]+", "regex:^web$"},
+				Exclude: []NonEmptyString{"regex:^web$", "*"},
+			},
+			wantFields: []string{"sel.include[0]", "sel.include[1]"},
+		}, {
+			name: "invalid namespace name",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"Kube_System", "app-*"},
+			},
+			wantFields: []string{"sel.include[0]"},
+		}, {
+			name: "only negated patterns",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"!default"},
+			},
+			wantFields: []string{"sel.include"},
+		}, {
+			name: "malformed patterns are left to Validate",
+			sel: NamespaceSelector{
+				Include: []NonEmptyString{"kube-[a", "Kube_System"},
+			},
+			wantFields: []string{},
+		}, {
+			name: "contradicting labels",
+			sel: NamespaceSelector{
+				Include:     []NonEmptyString{"*"},
+				MatchLabels: map[string]string{"env": "prod", "team": "a"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"prod"}},
+					{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"a", "b"}},
+					{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+					{Key: "tier", Operator: metav1.LabelSelectorOpDoesNotExist},
+					{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"east"}},
+					{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"west"}},
+				},
+			},
+			wantFields: []string{"sel", "sel", "sel"},
+		},
+	}
+
+	for _, tc := range tests {
+		got := make([]string, 0)
+		for _, err := range tc.sel.Warnings(field.NewPath("sel")) {
+			got = append(got, err.Field)
+		}
+
+		if !reflect.DeepEqual(got, tc.wantFields) {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.wantFields, got)
+		}
+	}
+}
+
+func TestUnsatisfiableLabels(t *testing.T) {
+	sel := NamespaceSelector{
+		MatchLabels: map[string]string{"env": "prod"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev", "test"}},
+			{Key: "owner", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"nobody"}},
+			{Key: "owner", Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+
+	want := []string{"env"}
+	if got := sel.unsatisfiableLabels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected: %v, got: %v", want, got)
+	}
+}
@@ -26,6 +26,75 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceHistory) DeepCopyInto(out *ComplianceHistory) {
+	*out = *in
+	in.LastTimestamp.DeepCopyInto(&out.LastTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceHistory.
+func (in *ComplianceHistory) DeepCopy() *ComplianceHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompliancyDetails) DeepCopyInto(out *CompliancyDetails) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]TemplateDetails, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompliancyDetails.
+func (in *CompliancyDetails) DeepCopy() *CompliancyDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(CompliancyDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvaluationInterval) DeepCopyInto(out *EvaluationInterval) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvaluationInterval.
+func (in *EvaluationInterval) DeepCopy() *EvaluationInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(EvaluationInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryEntry) DeepCopyInto(out *HistoryEntry) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryEntry.
+func (in *HistoryEntry) DeepCopy() *HistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
 	*out = *in
@@ -39,6 +108,20 @@ func (in *NamespaceSelector) DeepCopyInto(out *NamespaceSelector) {
 		*out = make([]NonEmptyString, len(*in))
 		copy(*out, *in)
 	}
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MatchExpressions != nil {
+		in, out := &in.MatchExpressions, &out.MatchExpressions
+		*out = make([]v1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceSelector.
@@ -153,6 +236,7 @@ func (in *PolicyTypeSpec) DeepCopyInto(out *PolicyTypeSpec) {
 			(*out)[key] = val
 		}
 	}
+	out.EvaluationInterval = in.EvaluationInterval
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyTypeSpec.
@@ -168,6 +252,7 @@ func (in *PolicyTypeSpec) DeepCopy() *PolicyTypeSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PolicyTypeStatus) DeepCopyInto(out *PolicyTypeStatus) {
 	*out = *in
+	in.CompliancyDetails.DeepCopyInto(&out.CompliancyDetails)
 	if in.RelatedObjects != nil {
 		in, out := &in.RelatedObjects, &out.RelatedObjects
 		*out = make([]RelatedObject, len(*in))
@@ -180,6 +265,14 @@ func (in *PolicyTypeStatus) DeepCopyInto(out *PolicyTypeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]HistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastEvaluated.DeepCopyInto(&out.LastEvaluated)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyTypeStatus.
@@ -207,3 +300,48 @@ func (in *RelatedObject) DeepCopy() *RelatedObject {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateCondition) DeepCopyInto(out *TemplateCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateCondition.
+func (in *TemplateCondition) DeepCopy() *TemplateCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateDetails) DeepCopyInto(out *TemplateDetails) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]TemplateCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ComplianceHistory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateDetails.
+func (in *TemplateDetails) DeepCopy() *TemplateDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateDetails)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//+kubebuilder:validation:MinLength=1
+type NonEmptyString string
+
+// ComplianceState shows the state of enforcement
+//+kubebuilder:validation:Enum=Compliant;NonCompliant;UnknownCompliancy
+type ComplianceState string
+
+const (
+	Compliant         ComplianceState = "Compliant"
+	NonCompliant      ComplianceState = "NonCompliant"
+	UnknownCompliancy ComplianceState = "UnknownCompliancy"
+)
+
+// Severity is how serious the situation is when the policy is not compliant.
+//+kubebuilder:validation:Enum=low;medium;high;critical
+type Severity string
+
+const (
+	SeverityLow      Severity = "low"
+	SeverityMedium   Severity = "medium"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+// RemediationAction indicates what the policy controller should do when the
+// policy is not compliant.
+//+kubebuilder:validation:Enum=inform;enforce;dryrun
+type RemediationAction string
+
+const (
+	RemediationInform  RemediationAction = "inform"
+	RemediationEnforce RemediationAction = "enforce"
+	RemediationDryRun  RemediationAction = "dryrun"
+)
+
+// PolicyTypeSpec includes all fields that should be implemented in the spec of
+// all policy types in the policy framework.
+type PolicyTypeSpec struct {
+	// Severity is how serious the situation is when the policy is not
+	// compliant. Accepted values include: low, medium, high, and critical.
+	Severity Severity `json:"severity,omitempty"`
+
+	// RemediationAction indicates what the policy controller should do when the
+	// policy is not compliant. Accepted values include inform, enforce, and
+	// dryrun. With dryrun, the controller reports what enforcing the policy
+	// would change, without changing anything. Note that not all policy
+	// controllers will attempt to automatically remediate a policy, even when
+	// set to "enforce".
+	RemediationAction RemediationAction `json:"remediationAction,omitempty"`
+
+	// NamespaceSelector indicates which namespaces on the cluster this policy
+	// should apply to, when the policy applies to namespaced objects.
+	//+kubebuilder:validation:Required
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+
+	// LabelSelector is a map of labels and values for the resources that the
+	// policy should apply to. Not all policy controllers use this field, but
+	// if they do, the resources must match all labels specified here.
+	LabelSelector map[string]NonEmptyString `json:"labelSelector,omitempty"`
+
+	// EvaluationInterval configures how often the policy should be evaluated
+	// again after it has been evaluated, depending on its compliance. When it is
+	// not set, the policy is only evaluated again when something triggers it,
+	// like a change to the policy or to a namespace it selects.
+	EvaluationInterval EvaluationInterval `json:"evaluationInterval,omitempty"`
+}
+
+// EvaluationInterval configures how long to wait between evaluations of a
+// policy, with separate intervals for when it is compliant and not compliant.
+// Each interval is a duration like "30s", "10m", or "1h30m", or "never" to stop
+// evaluating the policy periodically while it has that compliance.
+type EvaluationInterval struct {
+	// Compliant is the interval to use when the policy is compliant.
+	//+kubebuilder:validation:Pattern=`^(?:never|(?:[0-9]+(?:\.[0-9]+)?(?:h|m|s|ms|us|ns))+)$`
+	Compliant string `json:"compliant,omitempty"`
+
+	// NonCompliant is the interval to use when the policy is not compliant, or
+	// when its compliance is unknown.
+	//+kubebuilder:validation:Pattern=`^(?:never|(?:[0-9]+(?:\.[0-9]+)?(?:h|m|s|ms|us|ns))+)$`
+	NonCompliant string `json:"noncompliant,omitempty"`
+}
+
+type NamespaceSelector struct {
+	// Include is a list of namespaces the policy should apply to. UNIX style
+	// wildcards will be expanded, for example "kube-*" will include both
+	// "kube-system" and "kube-public". Items prefixed with "regex:" are instead
+	// regular expressions which must match the whole namespace name, and items
+	// prefixed with "!" are exceptions to the items before them in the list.
+	//+kubebuilder:validation:Required
+	//+kubebuilder:validation:MinItems=1
+	Include []NonEmptyString `json:"include,omitempty"`
+
+	// Exclude is a list of namespaces the policy should _not_ apply to. UNIX
+	// style wildcards will be expanded, for example "kube-*" will exclude both
+	// "kube-system" and "kube-public". The "regex:" and "!" prefixes work the
+	// same as in Include, so ["openshift-*", "!openshift-logging"] excludes
+	// all of the "openshift-" namespaces except for "openshift-logging".
+	Exclude []NonEmptyString `json:"exclude,omitempty"`
+
+	// MatchLabels is a map of labels and values for the namespaces the policy
+	// should apply to. A namespace must have all of these labels, and must also
+	// be selected by the Include and Exclude lists.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+
+	// MatchExpressions is a list of label selector requirements for the
+	// namespaces the policy should apply to, using the same semantics as in a
+	// standard Kubernetes LabelSelector. A namespace must meet all of these
+	// requirements, and must also be selected by the Include and Exclude lists.
+	MatchExpressions []metav1.LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// PolicyTypeStatus includes fields that are useful for policy types in the
+// policy framework to implement in order to report status.
+type PolicyTypeStatus struct {
+	// ComplianceState indicates whether the policy is compliant or not.
+	// Accepted values include: Compliant, NonCompliant, and UnknownCompliancy
+	ComplianceState ComplianceState `json:"complianceState,omitempty"`
+
+	// CompliancyDetails describes the compliance of each template in the
+	// policy, in a common format which can be rendered the same way for any
+	// policy type.
+	CompliancyDetails CompliancyDetails `json:"compliancyDetails,omitempty"`
+
+	// RelatedObjects are objects on the cluster that were examined in order to
+	// determine compliance. Often these are objects that cause a violation, but
+	// not always.
+	RelatedObjects []RelatedObject `json:"relatedObjects,omitempty"`
+
+	// Conditions represent the latest available observations of an object's state
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// History is a list of the recent changes to the compliance of the policy,
+	// with the most recent first. It is trimmed to a limited length.
+	History []HistoryEntry `json:"history,omitempty"`
+
+	// LastEvaluated is when the policy was last evaluated. It is used with the
+	// EvaluationInterval in the spec to determine when to evaluate it again.
+	LastEvaluated metav1.Time `json:"lastEvaluated,omitempty"`
+
+	// ObservedGeneration is the generation of the policy that was evaluated to
+	// determine this status. When it does not match the generation of the
+	// policy, the status does not reflect the current spec.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// HistoryEntry records a change to the compliance of a policy.
+type HistoryEntry struct {
+	// Timestamp is when the change was observed.
+	Timestamp metav1.Time `json:"timestamp,omitempty"`
+
+	// ComplianceState is the compliance of the policy after the change.
+	ComplianceState ComplianceState `json:"complianceState,omitempty"`
+
+	// Reason is the reason on the Compliant condition after the change.
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the message on the Compliant condition after the change.
+	Message string `json:"message,omitempty"`
+}
+
+// CompliancyDetails is a versioned list of the templates in a policy, with the
+// details of the compliance of each one.
+type CompliancyDetails struct {
+	// Version is the version of this format, so that consumers can tell how to
+	// render the details. The current version is "v1".
+	Version string `json:"version,omitempty"`
+
+	// Templates is the list of templates in the policy.
+	Templates []TemplateDetails `json:"templates,omitempty"`
+}
+
+// TemplateDetails describes the compliance of one template in a policy.
+type TemplateDetails struct {
+	// Name identifies the template within the policy.
+	Name string `json:"name,omitempty"`
+
+	// ComplianceState indicates whether the template is compliant or not.
+	ComplianceState ComplianceState `json:"complianceState,omitempty"`
+
+	// Conditions are the observations which determined the compliance of the
+	// template, like the violations that were found.
+	Conditions []TemplateCondition `json:"conditions,omitempty"`
+
+	// History is the list of compliance changes of the template, with the most
+	// recent first.
+	History []ComplianceHistory `json:"history,omitempty"`
+}
+
+// TemplateCondition is an observation about a template. Unlike a
+// metav1.Condition, the Reason is free-form text, so that conditions from
+// existing policy controllers can be represented.
+type TemplateCondition struct {
+	Type               string                 `json:"type,omitempty"`
+	Status             metav1.ConditionStatus `json:"status,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+}
+
+// ComplianceHistory is a single compliance event in the history of a template.
+type ComplianceHistory struct {
+	// LastTimestamp is the last time the event was emitted.
+	LastTimestamp metav1.Time `json:"lastTimestamp,omitempty"`
+
+	// Message is the full message of the event, including the compliance prefix.
+	Message string `json:"message,omitempty"`
+
+	// EventName is the name of the event object, which can be used to find it.
+	EventName string `json:"eventName,omitempty"`
+}
+
+type RelatedObject struct {
+	Object          ObjectRef       `json:"object,omitempty"`
+	ComplianceState ComplianceState `json:"complianceState,omitempty"`
+	Reason          NonEmptyString  `json:"reason,omitempty"`
+
+	// Diff is a unified diff of the changes that enforcing the policy would make
+	// to the object, when the RemediationAction is dryrun.
+	Diff string `json:"diff,omitempty"`
+}
+
+type ObjectRef struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        ObjectMetadata `json:"metadata,omitempty"`
+}
+
+// ObjectMetadata contains the resource metadata for an object being processed by the policy
+type ObjectMetadata struct {
+	// Name of the referent. More info:
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/names/#names
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the referent. More info:
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/namespaces/
+	Namespace string `json:"namespace,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+
+// PolicyType is the Schema for the policytypes API
+type PolicyType struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PolicyTypeSpec   `json:"spec,omitempty"`
+	Status PolicyTypeStatus `json:"status,omitempty"`
+}
+
+// PolicyTyper is implemented by all v1beta1 policy types in the policy
+// framework. The PolicySpec and PolicyStatus methods should have pointer
+// receivers, so that changes made through the returned pointers are reflected
+// in the object.
+//+kubebuilder:object:generate=false
+type PolicyTyper interface {
+	client.Object
+	PolicySpec() *PolicyTypeSpec
+	PolicyStatus() *PolicyTypeStatus
+}
+
+func (p *PolicyType) PolicySpec() *PolicyTypeSpec {
+	return &p.Spec
+}
+
+func (p *PolicyType) PolicyStatus() *PolicyTypeStatus {
+	return &p.Status
+}
+
+// Hub marks this type as a conversion hub: other versions of PolicyType are
+// converted to and from this version.
+func (*PolicyType) Hub() {}
+
+// blank assignment to verify that PolicyType implements PolicyTyper
+var _ PolicyTyper = &PolicyType{}
+
+//+kubebuilder:object:root=true
+
+// PolicyTypeList contains a list of PolicyType
+type PolicyTypeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PolicyType `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PolicyType{}, &PolicyTypeList{})
+}
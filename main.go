@@ -14,4 +14,28 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Command policyctl works with policies in the policy framework without a
+// cluster. Run it without arguments to see the available commands.
 package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+
+	"github.com/JustinKuli/policy-framework/pkg/policyctl"
+)
+
+//go:embed config/crd/bases/*.yaml
+var crds embed.FS
+
+func main() {
+	frameworkCRDs, err := fs.Sub(crds, "config/crd/bases")
+	if err != nil {
+		panic(err)
+	}
+
+	policyctl.FrameworkCRDs = frameworkCRDs
+
+	os.Exit(policyctl.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
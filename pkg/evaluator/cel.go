@@ -0,0 +1,213 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/golang/groupcache/lru"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// CELObjectVariable is the name of the variable which holds the object being
+// checked in a CELRule expression.
+const CELObjectVariable = "object"
+
+// ReasonRuleSatisfied is the reason used in the RelatedObjects returned by the
+// CELEvaluator for objects which satisfy the rule.
+const ReasonRuleSatisfied = "rule satisfied"
+
+// CELRule is a CEL expression which every object of a kind must satisfy. For
+// example, to require at least two replicas in every Deployment, use the
+// APIVersion "apps/v1", the Kind "Deployment", and the Expression
+// "object.spec.replicas >= 2".
+type CELRule struct {
+	// APIVersion and Kind are the type of the objects to check. Namespaced kinds
+	// are checked in every namespace selected by the policy.
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Expression must evaluate to true for each object to be compliant. The
+	// object is available in the expression as a variable named "object".
+	Expression string `json:"expression"`
+
+	// Message is used for the objects which do not satisfy the rule. When it
+	// is empty, a message including the Expression is used.
+	Message string `json:"message,omitempty"`
+}
+
+// celEnv is the environment used to compile all CELRule expressions.
+var celEnv = func() *cel.Env {
+	env, err := cel.NewEnv(cel.Declarations(decls.NewVar(CELObjectVariable, decls.Dyn)))
+	if err != nil {
+		panic(fmt.Sprintf("unable to create the CEL environment: %v", err))
+	}
+
+	return env
+}()
+
+// CompileCELExpression compiles the expression, and checks that it can result
+// in a bool. It can be used when a policy is created, so that invalid
+// expressions are rejected before they are evaluated.
+func CompileCELExpression(expression string) (cel.Program, error) {
+	ast, issues := celEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+
+	resultType := ast.ResultType()
+	if resultType.GetPrimitive() != exprpb.Type_BOOL && resultType.GetDyn() == nil {
+		return nil, fmt.Errorf("the expression must result in a bool")
+	}
+
+	return celEnv.Program(ast)
+}
+
+// ValidateCELRules checks that each of the rules has a type and an expression
+// which compiles. Policy types with CELRules in their spec can use this in
+// their validating webhooks, in addition to PolicyTypeSpec.Validate.
+func ValidateCELRules(rules []CELRule, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, rule := range rules {
+		rulePath := path.Index(i)
+
+		if rule.APIVersion == "" {
+			errs = append(errs, field.Required(rulePath.Child("apiVersion"), ""))
+		}
+
+		if rule.Kind == "" {
+			errs = append(errs, field.Required(rulePath.Child("kind"), ""))
+		}
+
+		if _, err := CompileCELExpression(rule.Expression); err != nil {
+			errs = append(errs, field.Invalid(rulePath.Child("expression"), rule.Expression, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// CELEvaluator determines compliance by evaluating CELRules against the
+// objects on the cluster. The client needs access to list the kinds in the
+// rules, and to list namespaces; it is also used to find out whether a kind is
+// namespaced.
+type CELEvaluator struct {
+	client.Client
+
+	mu       sync.Mutex
+	programs *lru.Cache
+}
+
+// Evaluate checks each of the rules against the objects of its kind which are
+// selected by the policy's NamespaceSelector and LabelSelector, and returns
+// values matching the reconciler's EvaluateFunc. Each object is reported as a
+// RelatedObject, and the policy is NonCompliant with ReasonViolationsFound if
+// any object does not satisfy a rule. An expression which fails to evaluate
+// for an object, for example because a field is missing, is a violation.
+func (e *CELEvaluator) Evaluate(
+	ctx context.Context, spec *v1alpha1.PolicyTypeSpec, rules ...CELRule,
+) (v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error) {
+	related := make([]v1alpha1.RelatedObject, 0)
+	violations := make([]string, 0)
+
+	for _, rule := range rules {
+		program, err := e.program(rule.Expression)
+		if err != nil {
+			return v1alpha1.UnknownCompliancy, nil, "", "", fmt.Errorf("invalid expression '%v': %w", rule.Expression, err)
+		}
+
+		gvk := schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind)
+
+		namespaces, err := targetNamespaces(ctx, e, spec, gvk, "")
+		if err != nil {
+			return v1alpha1.UnknownCompliancy, nil, "", "", err
+		}
+
+		for _, ns := range namespaces {
+			objs, err := fetchTargets(ctx, e, gvk, ns, "", specLabelSelector(spec))
+			if err != nil {
+				return v1alpha1.UnknownCompliancy, nil, "", "", err
+			}
+
+			for _, obj := range objs {
+				violation := ""
+
+				out, _, err := program.Eval(map[string]interface{}{CELObjectVariable: obj.Object})
+				switch {
+				case err != nil:
+					violation = fmt.Sprintf("unable to evaluate '%v': %v", rule.Expression, err)
+				case out.Value() != true:
+					violation = rule.Message
+					if violation == "" {
+						violation = fmt.Sprintf("does not satisfy '%v'", rule.Expression)
+					}
+				}
+
+				if violation == "" {
+					related = append(related, relatedObject(obj, ns, obj.GetName(), v1alpha1.Compliant, ReasonRuleSatisfied))
+
+					continue
+				}
+
+				related = append(related, relatedObject(obj, ns, obj.GetName(), v1alpha1.NonCompliant, violation))
+				violations = append(violations,
+					fmt.Sprintf("%v %v %v", rule.Kind, objectName(ns, obj.GetName()), violation))
+			}
+		}
+	}
+
+	if len(violations) != 0 {
+		return v1alpha1.NonCompliant, related, v1alpha1.ReasonViolationsFound, strings.Join(violations, "; "), nil
+	}
+
+	return v1alpha1.Compliant, related, v1alpha1.ReasonPolicyCompliant, "all objects satisfy the rules", nil
+}
+
+// program returns the compiled expression, compiling it only when it is not in
+// the evaluator's cache.
+func (e *CELEvaluator) program(expression string) (cel.Program, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.programs == nil {
+		e.programs = newCompiledCache()
+	}
+
+	if program, found := e.programs.Get(expression); found {
+		return program.(cel.Program), nil
+	}
+
+	program, err := CompileCELExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	e.programs.Add(expression, program)
+
+	return program, nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestCELEvaluator(t *testing.T) {
+	deployment := func(ns, name string, replicas int32, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(replicas)},
+		}
+	}
+
+	e := &CELEvaluator{Client: testClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		deployment("default", "single", 1, nil),
+		deployment("prod", "web", 3, map[string]string{"tier": "web"}),
+		deployment("prod", "worker", 2, nil),
+	)}
+
+	selectAll := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}
+	replicasRule := CELRule{APIVersion: "apps/v1", Kind: "Deployment", Expression: "object.spec.replicas >= 2"}
+
+	type test struct {
+		name        string
+		spec        v1alpha1.PolicyTypeSpec
+		rule        CELRule
+		wantState   v1alpha1.ComplianceState
+		wantMsg     string
+		wantRelated int
+		wantErr     bool
+	}
+
+	tests := []test{
+		{
+			name:        "violation in one namespace",
+			spec:        v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll},
+			rule:        replicasRule,
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Deployment default/single does not satisfy 'object.spec.replicas >= 2'",
+			wantRelated: 3,
+		}, {
+			name: "namespace selector",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"prod"}},
+			},
+			rule:        replicasRule,
+			wantState:   v1alpha1.Compliant,
+			wantMsg:     "all objects satisfy the rules",
+			wantRelated: 2,
+		}, {
+			name: "label selector and custom message",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: selectAll,
+				LabelSelector:     map[string]v1alpha1.NonEmptyString{"tier": "web"},
+			},
+			rule: CELRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Expression: "object.spec.replicas >= 5",
+				Message:    "needs at least 5 replicas",
+			},
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Deployment prod/web needs at least 5 replicas",
+			wantRelated: 1,
+		}, {
+			name: "missing field",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"default"}},
+			},
+			rule:        CELRule{APIVersion: "apps/v1", Kind: "Deployment", Expression: "object.spec.paused"},
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Deployment default/single unable to evaluate 'object.spec.paused': no such key: paused",
+			wantRelated: 1,
+		}, {
+			name:      "invalid expression",
+			spec:      v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll},
+			rule:      CELRule{APIVersion: "apps/v1", Kind: "Deployment", Expression: "object.spec.replicas >="},
+			wantState: v1alpha1.UnknownCompliancy,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		state, related, _, msg, err := e.Evaluate(context.TODO(), &tc.spec, tc.rule)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		if state != tc.wantState || msg != tc.wantMsg {
+			t.Errorf("test '%v' expected: %v '%v', got: %v '%v'", tc.name, tc.wantState, tc.wantMsg, state, msg)
+		}
+
+		if len(related) != tc.wantRelated {
+			t.Errorf("test '%v' expected %v related objects, got: %v", tc.name, tc.wantRelated, related)
+		}
+	}
+}
+
+func TestCELEvaluatorCacheSize(t *testing.T) {
+	defer func(orig int) { CompiledCacheSize = orig }(CompiledCacheSize)
+	CompiledCacheSize = 2
+
+	e := &CELEvaluator{Client: testClient()}
+	selectAll := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}
+	spec := &v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll}
+
+	for _, expression := range []string{"true", "false", "1 == 1", "true"} {
+		rule := CELRule{APIVersion: "v1", Kind: "ConfigMap", Expression: expression}
+		if _, _, _, _, err := e.Evaluate(context.TODO(), spec, rule); err != nil {
+			t.Fatalf("unexpected error for '%v': %v", expression, err)
+		}
+	}
+
+	if got := e.programs.Len(); got != 2 {
+		t.Errorf("expected 2 cached programs, got: %v", got)
+	}
+
+	if _, found := e.programs.Get("false"); found {
+		t.Error("expected the least recently used program to be dropped")
+	}
+}
+
+func TestValidateCELRules(t *testing.T) {
+	rules := []CELRule{
+		{APIVersion: "v1", Kind: "ConfigMap", Expression: "has(object.data)"},
+		{APIVersion: "v1", Kind: "ConfigMap", Expression: "object.data +"},
+		{Expression: "'not a bool'"},
+	}
+
+	errs := ValidateCELRules(rules, field.NewPath("spec", "rules"))
+
+	want := []string{
+		"spec.rules[1].expression",
+		"spec.rules[2].apiVersion",
+		"spec.rules[2].kind",
+		"spec.rules[2].expression",
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("expected %v errors, got: %v", len(want), errs)
+	}
+
+	for i, err := range errs {
+		if err.Field != want[i] {
+			t.Errorf("expected error %v on field %v, got: %v", i, want[i], err.Field)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"reflect"
+)
+
+// matchesTemplate returns true if the existing object matches the template. The
+// apiVersion and kind are not compared, and only the labels and annotations in
+// the template's metadata are compared, always as subsets. With exact, the
+// lists in the template must match the lists in the object exactly.
+func matchesTemplate(template, existing map[string]interface{}, exact bool) bool {
+	for key, tmplVal := range template {
+		switch key {
+		case "apiVersion", "kind":
+			continue
+		case "metadata":
+			if !matchesMetadata(tmplVal, existing[key]) {
+				return false
+			}
+
+			continue
+		}
+
+		existingVal, found := existing[key]
+		if !found {
+			if tmplVal == nil {
+				continue
+			}
+
+			return false
+		}
+
+		if !equalValues(tmplVal, existingVal, exact) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesMetadata compares the labels and annotations of the metadata.
+func matchesMetadata(template, existing interface{}) bool {
+	tmplMeta, ok := template.(map[string]interface{})
+	if !ok {
+		return true
+	}
+
+	existingMeta, _ := existing.(map[string]interface{})
+
+	for _, key := range []string{"labels", "annotations"} {
+		if tmplVal, found := tmplMeta[key]; found && !equalValues(tmplVal, existingMeta[key], false) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equalValues compares a value from the template with a value from the object.
+// Maps in the template must be a subset of maps in the object, and lists in the
+// template must be contained in lists in the object, in any order. With exact,
+// the lists must also have the same length, so the object can not have extra
+// items. Maps are still compared as subsets with exact, since the API server
+// defaults fields in objects which are not in the template, like the
+// imagePullPolicy of a container. Numbers are compared by value, regardless of
+// their types.
+func equalValues(tmplVal, existingVal interface{}, exact bool) bool {
+	switch tmpl := tmplVal.(type) {
+	case map[string]interface{}:
+		existing, ok := existingVal.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for key, val := range tmpl {
+			existingItem, found := existing[key]
+			if !found {
+				if val == nil {
+					continue
+				}
+
+				return false
+			}
+
+			if !equalValues(val, existingItem, exact) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		existing, ok := existingVal.([]interface{})
+		if !ok {
+			return false
+		}
+
+		if exact && len(existing) != len(tmpl) {
+			return false
+		}
+
+		return containsAll(tmpl, existing, exact)
+	case nil:
+		return existingVal == nil
+	}
+
+	if tmplNum, ok := toFloat(tmplVal); ok {
+		existingNum, ok := toFloat(existingVal)
+
+		return ok && tmplNum == existingNum
+	}
+
+	return reflect.DeepEqual(tmplVal, existingVal)
+}
+
+// containsAll returns true if every item in the template list matches a
+// different item in the existing list.
+func containsAll(tmpl, existing []interface{}, exact bool) bool {
+	used := make([]bool, len(existing))
+
+	for _, tmplItem := range tmpl {
+		matched := false
+
+		for i, existingItem := range existing {
+			if !used[i] && equalValues(tmplItem, existingItem, exact) {
+				used[i] = true
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toFloat(val interface{}) (float64, bool) {
+	switch num := val.(type) {
+	case int:
+		return float64(num), true
+	case int32:
+		return float64(num), true
+	case int64:
+		return float64(num), true
+	case float32:
+		return float64(num), true
+	case float64:
+		return num, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"github.com/golang/groupcache/lru"
+)
+
+// CompiledCacheSize is the number of compiled items each evaluator keeps, like
+// the CEL programs of the CELEvaluator and the prepared Rego queries of the
+// RegoEvaluator, so that they are not compiled again on every evaluation. The
+// least recently used ones are dropped first. It is only read when an evaluator
+// is first used, and 0 means there is no limit.
+var CompiledCacheSize = 128
+
+// newCompiledCache returns an empty cache for compiled items, which holds up to
+// CompiledCacheSize of them.
+func newCompiledCache() *lru.Cache {
+	return lru.New(CompiledCacheSize)
+}
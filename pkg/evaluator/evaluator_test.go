@@ -0,0 +1,62 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewCompiledCache(t *testing.T) {
+	defer func(orig int) { CompiledCacheSize = orig }(CompiledCacheSize)
+
+	type test struct {
+		name        string
+		size        int
+		wantLen     int
+		wantEvicted bool
+	}
+
+	tests := []test{
+		{name: "bounded", size: 3, wantLen: 3, wantEvicted: true},
+		{name: "no limit", size: 0, wantLen: 5, wantEvicted: false},
+	}
+
+	for _, tc := range tests {
+		CompiledCacheSize = tc.size
+		cache := newCompiledCache()
+
+		for i := 0; i < 5; i++ {
+			cache.Add(fmt.Sprint(i), i)
+
+			// Using the first item keeps it from being the least recently used
+			cache.Get("0")
+		}
+
+		if got := cache.Len(); got != tc.wantLen {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.wantLen, got)
+		}
+
+		if _, found := cache.Get("0"); !found {
+			t.Errorf("test '%v' expected the recently used item to be kept", tc.name)
+		}
+
+		if _, found := cache.Get("1"); found != !tc.wantEvicted {
+			t.Errorf("test '%v' expected the least recently used item to be evicted: %v", tc.name, tc.wantEvicted)
+		}
+	}
+}
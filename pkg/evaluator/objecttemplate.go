@@ -0,0 +1,217 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package evaluator contains generic ways to determine the compliance of a
+// policy, which policy controllers can use in their Evaluate functions instead
+// of implementing the checks themselves.
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/pkg/remediation"
+)
+
+// Reasons used in the RelatedObjects returned by ObjectTemplateEvaluator.
+const (
+	ReasonFoundAsSpecified    = "found as specified"
+	ReasonFoundNotAsSpecified = "found but not as specified"
+	ReasonNotFound            = "not found"
+	ReasonFoundButUnwanted    = "found but should not exist"
+)
+
+// ObjectTemplate describes objects which must, or must not, exist on the
+// cluster.
+type ObjectTemplate struct {
+	// ComplianceType is how the objects on the cluster must relate to the
+	// ObjectDefinition for the policy to be compliant.
+	ComplianceType remediation.ComplianceType `json:"complianceType"`
+
+	// ObjectDefinition is the template for the objects. It must have an
+	// apiVersion and kind. When it has a name, only the object with that name is
+	// checked, otherwise every object of the kind is a candidate. When it has a
+	// namespace, only that namespace is checked, otherwise namespaced kinds are
+	// checked in each namespace selected by the policy's NamespaceSelector.
+	ObjectDefinition *unstructured.Unstructured `json:"objectDefinition"`
+}
+
+// ObjectTemplateEvaluator determines compliance by comparing objects on the
+// cluster with ObjectTemplates. The client needs access to get and list the
+// kinds in the templates, and to list namespaces; it is also used to find out
+// whether a kind is namespaced.
+type ObjectTemplateEvaluator struct {
+	client.Client
+}
+
+// templateResult collects what was found while checking the templates.
+type templateResult struct {
+	related    []v1alpha1.RelatedObject
+	violations []string
+	// foundViolation is true when an object was found that does not comply,
+	// as opposed to only missing objects.
+	foundViolation bool
+}
+
+// Evaluate checks each of the templates, and returns values matching the
+// reconciler's EvaluateFunc. The policy is NonCompliant if any template is not
+// satisfied in any of its namespaces. The reason is ReasonViolationsFound if an
+// object on the cluster caused a violation, ReasonNoCompliantObjects if the
+// only problem is that required objects are missing, and ReasonPolicyCompliant
+// otherwise. Objects on the cluster are compared with a semantic subset
+// comparison: for musthave, every field in the template must be in the object
+// with an equal value, and lists in the template must be contained in the
+// lists in the object; for mustonlyhave, the lists in the template must also
+// not have extra items in the object. Extra fields in maps are allowed for both,
+// since the API server adds defaulted fields to objects. Only objects with the
+// labels in the policy's LabelSelector are considered.
+func (e *ObjectTemplateEvaluator) Evaluate(
+	ctx context.Context, spec *v1alpha1.PolicyTypeSpec, templates ...ObjectTemplate,
+) (v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error) {
+	res := &templateResult{related: make([]v1alpha1.RelatedObject, 0)}
+
+	for _, tmpl := range templates {
+		if err := e.evaluateTemplate(ctx, spec, tmpl, res); err != nil {
+			return v1alpha1.UnknownCompliancy, nil, "", "", err
+		}
+	}
+
+	switch {
+	case res.foundViolation:
+		return v1alpha1.NonCompliant, res.related, v1alpha1.ReasonViolationsFound,
+			strings.Join(res.violations, "; "), nil
+	case len(res.violations) != 0:
+		return v1alpha1.NonCompliant, res.related, v1alpha1.ReasonNoCompliantObjects,
+			strings.Join(res.violations, "; "), nil
+	default:
+		return v1alpha1.Compliant, res.related, v1alpha1.ReasonPolicyCompliant,
+			"all objects are as specified", nil
+	}
+}
+
+func (e *ObjectTemplateEvaluator) evaluateTemplate(
+	ctx context.Context, spec *v1alpha1.PolicyTypeSpec, tmpl ObjectTemplate, res *templateResult,
+) error {
+	obj := tmpl.ObjectDefinition
+	if obj == nil || obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+		return fmt.Errorf("object templates must have an apiVersion and kind")
+	}
+
+	switch tmpl.ComplianceType {
+	case remediation.MustHave, remediation.MustOnlyHave, remediation.MustNotHave:
+	default:
+		return fmt.Errorf("unknown compliance type '%v'", tmpl.ComplianceType)
+	}
+
+	namespaces, err := targetNamespaces(ctx, e, spec, obj.GroupVersionKind(), obj.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	if len(namespaces) == 0 && tmpl.ComplianceType != remediation.MustNotHave {
+		res.violations = append(res.violations, fmt.Sprintf("no namespaces are selected for %v", obj.GetKind()))
+
+		return nil
+	}
+
+	selector := specLabelSelector(spec)
+	exact := tmpl.ComplianceType == remediation.MustOnlyHave
+
+	for _, ns := range namespaces {
+		candidates, err := fetchTargets(ctx, e, obj.GroupVersionKind(), ns, obj.GetName(), selector)
+		if err != nil {
+			return err
+		}
+
+		matching := make([]*unstructured.Unstructured, 0, len(candidates))
+		for _, candidate := range candidates {
+			if matchesTemplate(obj.Object, candidate.Object, exact) {
+				matching = append(matching, candidate)
+			}
+		}
+
+		if tmpl.ComplianceType == remediation.MustNotHave {
+			if len(matching) == 0 {
+				res.related = append(res.related, relatedObject(obj, ns, obj.GetName(), v1alpha1.Compliant, ReasonNotFound))
+
+				continue
+			}
+
+			res.foundViolation = true
+			for _, found := range matching {
+				res.related = append(res.related,
+					relatedObject(obj, ns, found.GetName(), v1alpha1.NonCompliant, ReasonFoundButUnwanted))
+				res.violations = append(res.violations,
+					fmt.Sprintf("%v %v exists but should not", obj.GetKind(), objectName(ns, found.GetName())))
+			}
+
+			continue
+		}
+
+		switch {
+		case len(matching) != 0:
+			for _, found := range matching {
+				res.related = append(res.related,
+					relatedObject(obj, ns, found.GetName(), v1alpha1.Compliant, ReasonFoundAsSpecified))
+			}
+		case len(candidates) != 0:
+			res.foundViolation = true
+			for _, found := range candidates {
+				res.related = append(res.related,
+					relatedObject(obj, ns, found.GetName(), v1alpha1.NonCompliant, ReasonFoundNotAsSpecified))
+				res.violations = append(res.violations,
+					fmt.Sprintf("%v %v does not match the template", obj.GetKind(), objectName(ns, found.GetName())))
+			}
+		default:
+			res.related = append(res.related, relatedObject(obj, ns, obj.GetName(), v1alpha1.NonCompliant, ReasonNotFound))
+			res.violations = append(res.violations,
+				fmt.Sprintf("%v %v not found", obj.GetKind(), objectName(ns, obj.GetName())))
+		}
+	}
+
+	return nil
+}
+
+func relatedObject(
+	tmpl *unstructured.Unstructured, ns, name string, state v1alpha1.ComplianceState, reason string,
+) v1alpha1.RelatedObject {
+	return v1alpha1.RelatedObject{
+		Object: v1alpha1.ObjectRef{
+			TypeMeta: metav1.TypeMeta{APIVersion: tmpl.GetAPIVersion(), Kind: tmpl.GetKind()},
+			Metadata: v1alpha1.ObjectMetadata{Name: name, Namespace: ns},
+		},
+		ComplianceState: state,
+		Reason:          v1alpha1.NonEmptyString(reason),
+	}
+}
+
+func objectName(ns, name string) string {
+	if name == "" {
+		name = "(any)"
+	}
+
+	if ns == "" {
+		return name
+	}
+
+	return ns + "/" + name
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/pkg/remediation"
+)
+
+func testClient(objs ...client.Object) client.Client {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(appsv1.SchemeGroupVersion.WithKind("Deployment"), meta.RESTScopeNamespace)
+
+	return fake.NewClientBuilder().WithRESTMapper(mapper).WithObjects(objs...).Build()
+}
+
+func template(complianceType remediation.ComplianceType, obj map[string]interface{}) ObjectTemplate {
+	return ObjectTemplate{ComplianceType: complianceType, ObjectDefinition: &unstructured.Unstructured{Object: obj}}
+}
+
+func configMapTemplate(name, namespace string, data map[string]interface{}) map[string]interface{} {
+	metadata := map[string]interface{}{}
+	if name != "" {
+		metadata["name"] = name
+	}
+
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	obj := map[string]interface{}{"apiVersion": "v1", "kind": "ConfigMap", "metadata": metadata}
+	if data != nil {
+		obj["data"] = data
+	}
+
+	return obj
+}
+
+func TestObjectTemplateEvaluator(t *testing.T) {
+	e := &ObjectTemplateEvaluator{Client: testClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default", Labels: map[string]string{"app": "x"}},
+			Data:       map[string]string{"mode": "safe", "extra": "yes"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "prod"},
+			Data:       map[string]string{"mode": "fast"},
+		},
+	)}
+
+	allNamespaces := v1alpha1.PolicyTypeSpec{
+		NamespaceSelector: v1alpha1.NamespaceSelector{
+			Include: []v1alpha1.NonEmptyString{"*"},
+			Exclude: []v1alpha1.NonEmptyString{"kube-*"},
+		},
+	}
+
+	type test struct {
+		name       string
+		spec       v1alpha1.PolicyTypeSpec
+		templates  []ObjectTemplate
+		wantState  v1alpha1.ComplianceState
+		wantReason string
+		wantMsg    string
+		wantErr    bool
+	}
+
+	tests := []test{
+		{
+			name: "musthave subset in a given namespace",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustHave,
+				configMapTemplate("settings", "default", map[string]interface{}{"mode": "safe"}))},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantMsg:    "all objects are as specified",
+		}, {
+			name: "mustonlyhave with extra data",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustOnlyHave,
+				configMapTemplate("settings", "default", map[string]interface{}{"mode": "safe"}))},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantMsg:    "all objects are as specified",
+		}, {
+			name: "musthave in selected namespaces",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustHave,
+				configMapTemplate("settings", "", map[string]interface{}{"mode": "safe"}))},
+			wantState:  v1alpha1.NonCompliant,
+			wantReason: v1alpha1.ReasonViolationsFound,
+			wantMsg:    "ConfigMap prod/settings does not match the template",
+		}, {
+			name: "missing object",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustHave,
+				configMapTemplate("missing", "default", nil))},
+			wantState:  v1alpha1.NonCompliant,
+			wantReason: v1alpha1.ReasonNoCompliantObjects,
+			wantMsg:    "ConfigMap default/missing not found",
+		}, {
+			name: "unnamed musthave matches any object",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustHave,
+				configMapTemplate("", "prod", map[string]interface{}{"mode": "fast"}))},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantMsg:    "all objects are as specified",
+		}, {
+			name: "mustnothave",
+			spec: allNamespaces,
+			templates: []ObjectTemplate{template(remediation.MustNotHave,
+				configMapTemplate("", "", map[string]interface{}{"mode": "fast"}))},
+			wantState:  v1alpha1.NonCompliant,
+			wantReason: v1alpha1.ReasonViolationsFound,
+			wantMsg:    "ConfigMap prod/settings exists but should not",
+		}, {
+			name: "label selector hides objects",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: allNamespaces.NamespaceSelector,
+				LabelSelector:     map[string]v1alpha1.NonEmptyString{"app": "x"},
+			},
+			templates: []ObjectTemplate{template(remediation.MustNotHave,
+				configMapTemplate("settings", "prod", nil))},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantMsg:    "all objects are as specified",
+		}, {
+			name: "cluster-scoped object",
+			templates: []ObjectTemplate{template(remediation.MustHave, map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": "prod"},
+			})},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantMsg:    "all objects are as specified",
+		}, {
+			name: "no namespaces selected",
+			templates: []ObjectTemplate{template(remediation.MustHave,
+				configMapTemplate("settings", "", nil))},
+			wantState:  v1alpha1.NonCompliant,
+			wantReason: v1alpha1.ReasonNoCompliantObjects,
+			wantMsg:    "no namespaces are selected for ConfigMap",
+		}, {
+			name:      "unknown compliance type",
+			templates: []ObjectTemplate{template("musthavesome", configMapTemplate("settings", "default", nil))},
+			wantState: v1alpha1.UnknownCompliancy,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		state, _, reason, msg, err := e.Evaluate(context.TODO(), &tc.spec, tc.templates...)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		if state != tc.wantState || reason != tc.wantReason || msg != tc.wantMsg {
+			t.Errorf("test '%v' expected: %v %v '%v', got: %v %v '%v'", tc.name,
+				tc.wantState, tc.wantReason, tc.wantMsg, state, reason, msg)
+		}
+	}
+}
+
+// deploymentSpec returns the spec of a Deployment template with a container
+// for each image.
+func deploymentSpec(images ...string) map[string]interface{} {
+	containers := make([]interface{}, len(images))
+	for i, image := range images {
+		containers[i] = map[string]interface{}{"name": image, "image": image}
+	}
+
+	return map[string]interface{}{
+		"replicas": int64(1),
+		"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+			"spec":     map[string]interface{}{"containers": containers},
+		},
+	}
+}
+
+// defaultedDeploymentSpec returns the spec of a Deployment like the API server
+// returns it, with the defaulted fields.
+func defaultedDeploymentSpec(images ...string) map[string]interface{} {
+	spec := deploymentSpec(images...)
+	spec["progressDeadlineSeconds"] = int64(600)
+	spec["revisionHistoryLimit"] = int64(10)
+	spec["strategy"] = map[string]interface{}{
+		"type":          "RollingUpdate",
+		"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": "25%"},
+	}
+
+	podSpec := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	podSpec["dnsPolicy"] = "ClusterFirst"
+	podSpec["restartPolicy"] = "Always"
+	podSpec["schedulerName"] = "default-scheduler"
+	podSpec["securityContext"] = map[string]interface{}{}
+	podSpec["terminationGracePeriodSeconds"] = int64(30)
+
+	for _, container := range podSpec["containers"].([]interface{}) {
+		container := container.(map[string]interface{})
+		container["imagePullPolicy"] = "Always"
+		container["resources"] = map[string]interface{}{}
+		container["terminationMessagePath"] = "/dev/termination-log"
+		container["terminationMessagePolicy"] = "File"
+	}
+
+	return spec
+}
+
+func TestEqualValues(t *testing.T) {
+	type test struct {
+		name     string
+		tmpl     interface{}
+		existing interface{}
+		exact    bool
+		want     bool
+	}
+
+	list := func(items ...interface{}) []interface{} { return items }
+
+	tests := []test{
+		{"numbers of different types", int64(3), float64(3), false, true},
+		{"different numbers", int64(3), int64(4), false, false},
+		{"map subset", map[string]interface{}{"a": "b"}, map[string]interface{}{"a": "b", "c": "d"}, false, true},
+		{"exact map subset", map[string]interface{}{"a": "b"}, map[string]interface{}{"a": "b", "c": "d"}, true, true},
+		{"list subset in any order", list("b"), list("a", "b"), false, true},
+		{"exact list", list("b"), list("a", "b"), true, false},
+		{"exact list in any order", list("b", "a"), list("a", "b"), true, true},
+		{"duplicate items need distinct matches", list("a", "a"), list("a", "b"), false, false},
+		{
+			"nested subset in a list",
+			list(map[string]interface{}{"name": "c1"}),
+			list(map[string]interface{}{"name": "c1", "image": "nginx"}),
+			false, true,
+		},
+		{"type mismatch", "3", int64(3), false, false},
+		{"exact with defaulted fields", deploymentSpec("nginx"), defaultedDeploymentSpec("nginx"), true, true},
+		{"exact with an extra list item", deploymentSpec("nginx"), defaultedDeploymentSpec("nginx", "proxy"), true, false},
+		{"subset with an extra list item", deploymentSpec("nginx"), defaultedDeploymentSpec("nginx", "proxy"), false, true},
+	}
+
+	for _, tc := range tests {
+		if got := equalValues(tc.tmpl, tc.existing, tc.exact); got != tc.want {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/groupcache/lru"
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/rego"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// DefaultRegoPackage is the package which is queried for the deny and
+// violation rules when a RegoRule does not specify one.
+const DefaultRegoPackage = "main"
+
+// ReasonNoViolations is the reason used in the RelatedObjects returned by the
+// RegoEvaluator for objects which did not cause any deny or violation results.
+const ReasonNoViolations = "no violations"
+
+// RegoRule is a set of Rego modules which check every object of a kind. Each
+// object is passed as the input document, and each result of the deny or
+// violation rules in the Package is a violation. Results may be strings, like
+// in conftest, or objects with a "msg" field, like in Gatekeeper. For example:
+//
+//	package main
+//
+//	deny[msg] {
+//		input.spec.replicas < 2
+//		msg := "must have at least 2 replicas"
+//	}
+type RegoRule struct {
+	// APIVersion and Kind are the type of the objects to check. Namespaced kinds
+	// are checked in every namespace selected by the policy.
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+
+	// Package is the Rego package with the deny and violation rules, for
+	// example "kubernetes.admission". When it is empty, DefaultRegoPackage is
+	// used.
+	Package string `json:"package,omitempty"`
+
+	// Modules maps file names to the source of the Rego modules. The names are
+	// only used in error messages. Use RegoModulesFromConfigMap to load them
+	// from a ConfigMap.
+	Modules map[string]string `json:"modules"`
+}
+
+// query returns the Rego query for the rule's package.
+func (rule RegoRule) query() string {
+	return regoQuery(rule.Package)
+}
+
+// regoQuery returns the Rego query for the package, or for DefaultRegoPackage
+// when it is empty.
+func regoQuery(pkg string) string {
+	if pkg == "" {
+		pkg = DefaultRegoPackage
+	}
+
+	return "data." + pkg
+}
+
+// RegoModulesFromConfigMap returns the Rego modules in the data of the
+// ConfigMap, using the keys ending in ".rego" as their names. The client.Reader
+// needs access to get ConfigMaps in the namespace.
+func RegoModulesFromConfigMap(ctx context.Context, r client.Reader, ns, name string) (map[string]string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, cm); err != nil {
+		return nil, err
+	}
+
+	modules := make(map[string]string)
+
+	for key, val := range cm.Data {
+		if strings.HasSuffix(key, ".rego") {
+			modules[key] = val
+		}
+	}
+
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no Rego modules found in ConfigMap %v/%v", ns, name)
+	}
+
+	return modules, nil
+}
+
+// CompileRegoModules parses and compiles the modules, without evaluating them,
+// and checks that one of them defines the package, or DefaultRegoPackage when
+// the package is empty. It can be used when a policy is created, so that
+// invalid modules are rejected before they are evaluated.
+func CompileRegoModules(modules map[string]string, pkg string) error {
+	compiler, err := compileRegoModules(modules)
+	if err != nil {
+		return err
+	}
+
+	return checkRegoPackage(compiler, pkg)
+}
+
+// compileRegoModules parses and compiles the modules.
+func compileRegoModules(modules map[string]string) (*ast.Compiler, error) {
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("at least one module is required")
+	}
+
+	return ast.CompileModules(modules)
+}
+
+// checkRegoPackage returns an error if none of the compiled modules define the
+// package. Querying an undefined package would return no results, which would
+// otherwise look like there are no violations.
+func checkRegoPackage(compiler *ast.Compiler, pkg string) error {
+	query := regoQuery(pkg)
+
+	for _, module := range compiler.Modules {
+		if module.Package.Path.String() == query {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no module defines the package '%v'", strings.TrimPrefix(query, "data."))
+}
+
+// ValidateRegoRules checks that each of the rules has a type, modules which
+// compile, and a package which is defined in the modules. Policy types with
+// RegoRules in their spec can use this in their validating webhooks, in
+// addition to PolicyTypeSpec.Validate.
+func ValidateRegoRules(rules []RegoRule, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+
+	for i, rule := range rules {
+		rulePath := path.Index(i)
+
+		if rule.APIVersion == "" {
+			errs = append(errs, field.Required(rulePath.Child("apiVersion"), ""))
+		}
+
+		if rule.Kind == "" {
+			errs = append(errs, field.Required(rulePath.Child("kind"), ""))
+		}
+
+		compiler, err := compileRegoModules(rule.Modules)
+		if err != nil {
+			errs = append(errs, field.Invalid(rulePath.Child("modules"), moduleNames(rule.Modules), err.Error()))
+
+			continue
+		}
+
+		if err := checkRegoPackage(compiler, rule.Package); err != nil {
+			errs = append(errs, field.Invalid(rulePath.Child("package"), rule.Package, err.Error()))
+		}
+	}
+
+	return errs
+}
+
+// RegoEvaluator determines compliance by evaluating RegoRules against the
+// objects on the cluster. The modules are evaluated in-process, so no OPA
+// server is needed. The client needs access to list the kinds in the rules,
+// and to list namespaces; it is also used to find out whether a kind is
+// namespaced.
+type RegoEvaluator struct {
+	client.Client
+
+	mu      sync.Mutex
+	queries *lru.Cache
+}
+
+// Evaluate checks each of the rules against the objects of its kind which are
+// selected by the policy's NamespaceSelector and LabelSelector, and returns
+// values matching the reconciler's EvaluateFunc. Each object is reported as a
+// RelatedObject, with the deny and violation messages as its reason, and the
+// policy is NonCompliant with ReasonViolationsFound if any object has them. A
+// query which returns no results, because the package is undefined, is an
+// error.
+func (e *RegoEvaluator) Evaluate(
+	ctx context.Context, spec *v1alpha1.PolicyTypeSpec, rules ...RegoRule,
+) (v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error) {
+	related := make([]v1alpha1.RelatedObject, 0)
+	violations := make([]string, 0)
+
+	for _, rule := range rules {
+		query, err := e.prepare(ctx, rule)
+		if err != nil {
+			return v1alpha1.UnknownCompliancy, nil, "", "", fmt.Errorf("invalid Rego modules: %w", err)
+		}
+
+		gvk := schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind)
+
+		namespaces, err := targetNamespaces(ctx, e, spec, gvk, "")
+		if err != nil {
+			return v1alpha1.UnknownCompliancy, nil, "", "", err
+		}
+
+		for _, ns := range namespaces {
+			objs, err := fetchTargets(ctx, e, gvk, ns, "", specLabelSelector(spec))
+			if err != nil {
+				return v1alpha1.UnknownCompliancy, nil, "", "", err
+			}
+
+			for _, obj := range objs {
+				results, err := query.Eval(ctx, rego.EvalInput(obj.Object))
+				if err == nil && len(results) == 0 {
+					err = fmt.Errorf("the query %v is undefined", rule.query())
+				}
+
+				if err != nil {
+					return v1alpha1.UnknownCompliancy, nil, "", "", fmt.Errorf(
+						"unable to evaluate %v %v: %w", rule.Kind, objectName(ns, obj.GetName()), err)
+				}
+
+				msgs := regoMessages(results)
+				if len(msgs) == 0 {
+					related = append(related, relatedObject(obj, ns, obj.GetName(), v1alpha1.Compliant, ReasonNoViolations))
+
+					continue
+				}
+
+				violation := strings.Join(msgs, ", ")
+
+				related = append(related, relatedObject(obj, ns, obj.GetName(), v1alpha1.NonCompliant, violation))
+				violations = append(violations,
+					fmt.Sprintf("%v %v: %v", rule.Kind, objectName(ns, obj.GetName()), violation))
+			}
+		}
+	}
+
+	if len(violations) != 0 {
+		return v1alpha1.NonCompliant, related, v1alpha1.ReasonViolationsFound, strings.Join(violations, "; "), nil
+	}
+
+	return v1alpha1.Compliant, related, v1alpha1.ReasonPolicyCompliant, "no violations were found", nil
+}
+
+// prepare returns the prepared query for the rule, compiling its modules only
+// when they are not in the evaluator's cache.
+func (e *RegoEvaluator) prepare(ctx context.Context, rule RegoRule) (rego.PreparedEvalQuery, error) {
+	var key strings.Builder
+
+	key.WriteString(rule.query())
+
+	for _, name := range moduleNames(rule.Modules) {
+		key.WriteString("\x00" + name + "\x00" + rule.Modules[name])
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.queries == nil {
+		e.queries = newCompiledCache()
+	}
+
+	if query, found := e.queries.Get(key.String()); found {
+		return query.(rego.PreparedEvalQuery), nil
+	}
+
+	if err := CompileRegoModules(rule.Modules, rule.Package); err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+
+	opts := []func(*rego.Rego){rego.Query(rule.query())}
+	for _, name := range moduleNames(rule.Modules) {
+		opts = append(opts, rego.Module(name, rule.Modules[name]))
+	}
+
+	query, err := rego.New(opts...).PrepareForEval(ctx)
+	if err != nil {
+		return rego.PreparedEvalQuery{}, err
+	}
+
+	e.queries.Add(key.String(), query)
+
+	return query, nil
+}
+
+// moduleNames returns the sorted names of the modules.
+func moduleNames(modules map[string]string) []string {
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// regoMessages returns the sorted messages from the deny and violation rules
+// in the results of a package query.
+func regoMessages(results rego.ResultSet) []string {
+	msgs := make([]string, 0)
+
+	for _, result := range results {
+		for _, expr := range result.Expressions {
+			pkg, ok := expr.Value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			for _, ruleName := range []string{"deny", "violation"} {
+				values, ok := pkg[ruleName].([]interface{})
+				if !ok {
+					continue
+				}
+
+				for _, val := range values {
+					msgs = append(msgs, regoMessage(val))
+				}
+			}
+		}
+	}
+
+	sort.Strings(msgs)
+
+	return msgs
+}
+
+// regoMessage returns the message for a single deny or violation result.
+func regoMessage(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+
+	out, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+
+	return string(out)
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+const replicasModule = `package main
+
+deny[msg] {
+	input.spec.replicas < 2
+	msg := sprintf("%v has too few replicas", [input.metadata.name])
+}
+`
+
+const gatekeeperModule = `package k8srequiredlabels
+
+violation[{"msg": msg}] {
+	not input.metadata.labels.owner
+	msg := "missing the owner label"
+}
+`
+
+func TestRegoEvaluator(t *testing.T) {
+	deployment := func(ns, name string, replicas int32, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(replicas)},
+		}
+	}
+
+	e := &RegoEvaluator{Client: testClient(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod"}},
+		deployment("default", "single", 1, nil),
+		deployment("prod", "web", 3, map[string]string{"owner": "web-team"}),
+		deployment("prod", "worker", 2, nil),
+	)}
+
+	selectAll := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}
+	selectProd := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"prod"}}
+
+	type test struct {
+		name        string
+		spec        v1alpha1.PolicyTypeSpec
+		rule        RegoRule
+		wantState   v1alpha1.ComplianceState
+		wantMsg     string
+		wantRelated int
+		wantErr     bool
+	}
+
+	tests := []test{
+		{
+			name: "deny strings",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Modules:    map[string]string{"replicas.rego": replicasModule},
+			},
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Deployment default/single: single has too few replicas",
+			wantRelated: 3,
+		}, {
+			name: "namespace selector",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: selectProd},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Modules:    map[string]string{"replicas.rego": replicasModule},
+			},
+			wantState:   v1alpha1.Compliant,
+			wantMsg:     "no violations were found",
+			wantRelated: 2,
+		}, {
+			name: "violation objects in a custom package",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: selectProd},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Package:    "k8srequiredlabels",
+				Modules:    map[string]string{"labels.rego": gatekeeperModule},
+			},
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Deployment prod/worker: missing the owner label",
+			wantRelated: 2,
+		}, {
+			name: "label selector",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: selectAll,
+				LabelSelector:     map[string]v1alpha1.NonEmptyString{"owner": "web-team"},
+			},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Package:    "k8srequiredlabels",
+				Modules:    map[string]string{"labels.rego": gatekeeperModule},
+			},
+			wantState:   v1alpha1.Compliant,
+			wantMsg:     "no violations were found",
+			wantRelated: 1,
+		}, {
+			name: "invalid module",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Modules:    map[string]string{"broken.rego": "package main\n\ndeny[msg] {"},
+			},
+			wantState: v1alpha1.UnknownCompliancy,
+			wantErr:   true,
+		}, {
+			name: "misspelled package",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: selectProd},
+			rule: RegoRule{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Package:    "k8srequiredlabel",
+				Modules:    map[string]string{"labels.rego": gatekeeperModule},
+			},
+			wantState: v1alpha1.UnknownCompliancy,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		state, related, _, msg, err := e.Evaluate(context.TODO(), &tc.spec, tc.rule)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		if state != tc.wantState || msg != tc.wantMsg {
+			t.Errorf("test '%v' expected: %v '%v', got: %v '%v'", tc.name, tc.wantState, tc.wantMsg, state, msg)
+		}
+
+		if len(related) != tc.wantRelated {
+			t.Errorf("test '%v' expected %v related objects, got: %v", tc.name, tc.wantRelated, related)
+		}
+	}
+}
+
+func TestRegoModulesFromConfigMap(t *testing.T) {
+	c := testClient(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "rules", Namespace: "default"},
+		Data:       map[string]string{"replicas.rego": replicasModule, "README.md": "docs"},
+	})
+
+	got, err := RegoModulesFromConfigMap(context.TODO(), c, "default", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"replicas.rego": replicasModule}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected: %v, got: %v", want, got)
+	}
+
+	if _, err := RegoModulesFromConfigMap(context.TODO(), c, "default", "missing"); err == nil {
+		t.Error("expected an error for a missing ConfigMap")
+	}
+}
+
+func TestValidateRegoRules(t *testing.T) {
+	rules := []RegoRule{
+		{APIVersion: "v1", Kind: "ConfigMap", Modules: map[string]string{"ok.rego": replicasModule}},
+		{APIVersion: "v1", Kind: "ConfigMap", Modules: map[string]string{"broken.rego": "package main\n\ndeny[msg] {"}},
+		{},
+		{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+			Package:    "k8srequiredlabel",
+			Modules:    map[string]string{"labels.rego": gatekeeperModule},
+		},
+		{APIVersion: "v1", Kind: "ConfigMap", Modules: map[string]string{"labels.rego": gatekeeperModule}},
+	}
+
+	errs := ValidateRegoRules(rules, field.NewPath("spec", "rules"))
+
+	want := []string{
+		"spec.rules[1].modules",
+		"spec.rules[2].apiVersion",
+		"spec.rules[2].kind",
+		"spec.rules[2].modules",
+		"spec.rules[3].package",
+		"spec.rules[4].package",
+	}
+
+	if len(errs) != len(want) {
+		t.Fatalf("expected %v errors, got: %v", len(want), errs)
+	}
+
+	for i, err := range errs {
+		if err.Field != want[i] {
+			t.Errorf("expected error %v on field %v, got: %v", i, want[i], err.Field)
+		}
+	}
+}
+
+func TestRegoEvaluatorCacheSize(t *testing.T) {
+	defer func(orig int) { CompiledCacheSize = orig }(CompiledCacheSize)
+	CompiledCacheSize = 1
+
+	e := &RegoEvaluator{Client: testClient()}
+	selectAll := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}
+	spec := &v1alpha1.PolicyTypeSpec{NamespaceSelector: selectAll}
+
+	for _, module := range []string{replicasModule, gatekeeperModule} {
+		rule := RegoRule{APIVersion: "v1", Kind: "ConfigMap", Modules: map[string]string{"rule.rego": module}}
+		if module == gatekeeperModule {
+			rule.Package = "k8srequiredlabels"
+		}
+
+		if _, _, _, _, err := e.Evaluate(context.TODO(), spec, rule); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if got := e.queries.Len(); got != 1 {
+		t.Errorf("expected 1 cached query, got: %v", got)
+	}
+}
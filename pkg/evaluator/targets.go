@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evaluator
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// targetNamespaces returns the namespaces to check for objects of the kind. If
+// a namespace is given, only it is used. Otherwise, namespaced kinds use the
+// namespaces selected by the policy's NamespaceSelector, and cluster-scoped
+// kinds use a single empty namespace.
+func targetNamespaces(
+	ctx context.Context, c client.Client, spec *v1alpha1.PolicyTypeSpec, gvk schema.GroupVersionKind, ns string,
+) ([]string, error) {
+	if ns != "" {
+		return []string{ns}, nil
+	}
+
+	mapping, err := c.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return []string{""}, nil
+	}
+
+	return spec.NamespaceSelector.GetNamespaces(ctx, c)
+}
+
+// specLabelSelector returns a selector for the policy's LabelSelector, which
+// matches everything if it is empty.
+func specLabelSelector(spec *v1alpha1.PolicyTypeSpec) labels.Selector {
+	if len(spec.LabelSelector) == 0 {
+		return labels.Everything()
+	}
+
+	set := make(labels.Set, len(spec.LabelSelector))
+	for key, val := range spec.LabelSelector {
+		set[key] = string(val)
+	}
+
+	return labels.SelectorFromSet(set)
+}
+
+// fetchTargets returns the objects of the kind in the namespace which match
+// the selector: only the object with the given name if there is one, or every
+// object of the kind otherwise.
+func fetchTargets(
+	ctx context.Context, c client.Reader, gvk schema.GroupVersionKind, ns, name string, selector labels.Selector,
+) ([]*unstructured.Unstructured, error) {
+	if name != "" {
+		found := &unstructured.Unstructured{}
+		found.SetGroupVersionKind(gvk)
+
+		err := c.Get(ctx, client.ObjectKey{Namespace: ns, Name: name}, found)
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !selector.Matches(labels.Set(found.GetLabels())) {
+			return nil, nil
+		}
+
+		return []*unstructured.Unstructured{found}, nil
+	}
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+	err := c.List(ctx, list, client.InNamespace(ns), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]*unstructured.Unstructured, len(list.Items))
+	for i := range list.Items {
+		found[i] = &list.Items[i]
+	}
+
+	return found, nil
+}
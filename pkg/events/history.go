@@ -0,0 +1,198 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// TemplateHistory is the compliance history of one policy (a "template" of the
+// parent policy), built from the compliance events it sent to the parent. It
+// is meant to be written into the status of the parent policy.
+type TemplateHistory struct {
+	// PolicyNamespace and PolicyName identify the policy which sent the events.
+	PolicyNamespace string `json:"policyNamespace,omitempty"`
+	PolicyName      string `json:"policyName,omitempty"`
+
+	// ComplianceState is the compliance reported by the most recent event.
+	ComplianceState v1alpha1.ComplianceState `json:"compliant,omitempty"`
+
+	// History is the list of compliance events, with the most recent first.
+	History []v1alpha1.ComplianceHistory `json:"history,omitempty"`
+}
+
+// TemplateDetails returns the history in the format of the CompliancyDetails
+// in a policy status, named "namespace/name" after the policy which sent it.
+func (h TemplateHistory) TemplateDetails() v1alpha1.TemplateDetails {
+	return v1alpha1.TemplateDetails{
+		Name:            h.PolicyNamespace + "/" + h.PolicyName,
+		ComplianceState: h.ComplianceState,
+		History:         h.History,
+	}
+}
+
+// GetComplianceHistory lists the compliance events sent to the parent policy,
+// and returns the history of each policy which sent them, sorted by namespace
+// and name. Events which can not be parsed as compliance events are ignored.
+// If maxHistory is positive, each history is trimmed to that many events. The
+// events are listed in the parent's namespace and filtered by their involved
+// object, so this works with the manager's cached client without an index. The
+// client.Reader needs access to view events, like the access given by this
+// kubebuilder tag:
+// `//+kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch`
+func GetComplianceHistory(
+	ctx context.Context, r client.Reader, parent client.Object, maxHistory int,
+) ([]TemplateHistory, error) {
+	eventList := &corev1.EventList{}
+	if err := r.List(ctx, eventList, client.InNamespace(parent.GetNamespace())); err != nil {
+		return nil, err
+	}
+
+	entries := make(map[types.NamespacedName][]historyEntry)
+
+	for i := range eventList.Items {
+		event := &eventList.Items[i]
+
+		if !involvesParent(event, parent) {
+			continue
+		}
+
+		details, err := v1alpha1.ParseComplianceEvent(event)
+		if err != nil {
+			continue
+		}
+
+		key := types.NamespacedName{Namespace: details.PolicyNamespace, Name: details.PolicyName}
+
+		entries[key] = append(entries[key], historyEntry{
+			state: details.ComplianceState,
+			event: v1alpha1.ComplianceHistory{
+				LastTimestamp: eventTimestamp(event),
+				Message:       event.Message,
+				EventName:     event.Name,
+			},
+		})
+	}
+
+	result := make([]TemplateHistory, 0, len(entries))
+
+	for key, policyEntries := range entries {
+		sort.SliceStable(policyEntries, func(i, j int) bool {
+			return policyEntries[j].event.LastTimestamp.Before(&policyEntries[i].event.LastTimestamp)
+		})
+
+		if maxHistory > 0 && len(policyEntries) > maxHistory {
+			policyEntries = policyEntries[:maxHistory]
+		}
+
+		history := TemplateHistory{
+			PolicyNamespace: key.Namespace,
+			PolicyName:      key.Name,
+			ComplianceState: policyEntries[0].state,
+			History:         make([]v1alpha1.ComplianceHistory, len(policyEntries)),
+		}
+
+		for i, entry := range policyEntries {
+			history.History[i] = entry.event
+		}
+
+		result = append(result, history)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PolicyNamespace != result[j].PolicyNamespace {
+			return result[i].PolicyNamespace < result[j].PolicyNamespace
+		}
+
+		return result[i].PolicyName < result[j].PolicyName
+	})
+
+	return result, nil
+}
+
+// EnqueueParentPolicy returns an event handler for watching events, which
+// enqueues a request for the parent policy whenever a compliance event is sent
+// to it. Events which are not compliance events are ignored. It can be used in
+// a controller for the parent policy type like this:
+// `Watches(&source.Kind{Type: &corev1.Event{}}, events.EnqueueParentPolicy())`
+func EnqueueParentPolicy() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(parentPolicyRequests)
+}
+
+// parentPolicyRequests maps a compliance event to a request for the policy it
+// was sent to.
+func parentPolicyRequests(obj client.Object) []reconcile.Request {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return nil
+	}
+
+	if _, err := v1alpha1.ParseComplianceEvent(event); err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+	}}}
+}
+
+// historyEntry is a ComplianceHistory along with the compliance parsed from it.
+type historyEntry struct {
+	state v1alpha1.ComplianceState
+	event v1alpha1.ComplianceHistory
+}
+
+// involvesParent returns true if the event's involved object is the parent. The
+// UID is compared when both have one, otherwise the kind and name are used.
+func involvesParent(event *corev1.Event, parent client.Object) bool {
+	involved := event.InvolvedObject
+
+	if involved.UID != "" && parent.GetUID() != "" {
+		return involved.UID == parent.GetUID()
+	}
+
+	kind := parent.GetObjectKind().GroupVersionKind().Kind
+	if kind != "" && involved.Kind != kind {
+		return false
+	}
+
+	return involved.Namespace == parent.GetNamespace() && involved.Name == parent.GetName()
+}
+
+// eventTimestamp returns the last time the event was emitted, using whichever
+// timestamp field is set on the event.
+func eventTimestamp(event *corev1.Event) metav1.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp
+	case !event.EventTime.IsZero():
+		return metav1.NewTime(event.EventTime.Time)
+	default:
+		return event.CreationTimestamp
+	}
+}
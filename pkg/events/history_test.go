@@ -0,0 +1,227 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// eventWriter is an EventRecorder which turns the events it receives into
+// corev1.Events involving the given object, like the real recorder would.
+type eventWriter struct {
+	events []client.Object
+	now    time.Time
+}
+
+func (w *eventWriter) Event(object runtime.Object, eventtype, reason, message string) {
+	w.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+func (w *eventWriter) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	w.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+func (w *eventWriter) AnnotatedEventf(
+	object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{},
+) {
+	obj := object.(client.Object)
+	gvk := object.GetObjectKind().GroupVersionKind()
+
+	w.events = append(w.events, &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%v.%d", obj.GetName(), len(w.events)),
+			Namespace:   obj.GetNamespace(),
+			Annotations: annotations,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			UID:        obj.GetUID(),
+		},
+		Type:          eventtype,
+		Reason:        reason,
+		Message:       fmt.Sprintf(messageFmt, args...),
+		LastTimestamp: metav1.NewTime(w.now),
+	})
+}
+
+func TestGetComplianceHistory(t *testing.T) {
+	start := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	writer := &eventWriter{now: start}
+
+	newPolicy := func(name string, parent string) *v1alpha1.PolicyType {
+		return &v1alpha1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "policy.open-cluster-management.io/v1",
+					Kind:       "Policy",
+					Name:       parent,
+					UID:        types.UID("uid-" + parent),
+				}},
+			},
+		}
+	}
+
+	record := func(policy *v1alpha1.PolicyType, state v1alpha1.ComplianceState, annotated bool, msg string) {
+		policy.Status.ComplianceState = state
+
+		var err error
+		if annotated {
+			err = v1alpha1.RecordAnnotatedComplianceEvent(writer, policy, msg)
+		} else {
+			err = v1alpha1.RecordComplianceEvent(writer, policy, msg)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		writer.now = writer.now.Add(time.Minute)
+	}
+
+	first := newPolicy("first", "parent")
+	second := newPolicy("second", "parent")
+	other := newPolicy("other", "other-parent")
+
+	record(first, v1alpha1.NonCompliant, false, "missing pod")
+	record(second, v1alpha1.Compliant, true, "all good")
+	record(first, v1alpha1.Compliant, true, "found pod; it is running")
+	record(other, v1alpha1.NonCompliant, false, "not for this parent")
+	record(first, v1alpha1.NonCompliant, false, "pod was deleted")
+
+	// An event for the parent which is not a compliance event
+	unrelated := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "parent.unrelated", Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Policy", Namespace: "default", Name: "parent", UID: "uid-parent",
+		},
+		Reason:  "PolicyPropagation",
+		Message: "Policy default/parent was propagated",
+	}
+
+	c := fake.NewClientBuilder().WithObjects(append(writer.events, unrelated)...).Build()
+
+	parent := &v1alpha1.PolicyType{
+		ObjectMeta: metav1.ObjectMeta{Name: "parent", Namespace: "default", UID: "uid-parent"},
+	}
+
+	ts := func(minutes int) metav1.Time {
+		return metav1.NewTime(start.Add(time.Duration(minutes) * time.Minute))
+	}
+
+	want := []TemplateHistory{{
+		PolicyNamespace: "default",
+		PolicyName:      "first",
+		ComplianceState: v1alpha1.NonCompliant,
+		History: []v1alpha1.ComplianceHistory{
+			{LastTimestamp: ts(4), Message: "NonCompliant; pod was deleted", EventName: "parent.4"},
+			{LastTimestamp: ts(2), Message: "Compliant; found pod; it is running", EventName: "parent.2"},
+			{LastTimestamp: ts(0), Message: "NonCompliant; missing pod", EventName: "parent.0"},
+		},
+	}, {
+		PolicyNamespace: "default",
+		PolicyName:      "second",
+		ComplianceState: v1alpha1.Compliant,
+		History: []v1alpha1.ComplianceHistory{
+			{LastTimestamp: ts(1), Message: "Compliant; all good", EventName: "parent.1"},
+		},
+	}}
+
+	got, err := GetComplianceHistory(context.TODO(), c, parent, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The fake client serializes the events, so the timestamps lose their location
+	for _, history := range got {
+		for i := range history.History {
+			history.History[i].LastTimestamp = metav1.NewTime(history.History[i].LastTimestamp.UTC())
+		}
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected history: %+v, got: %+v", want, got)
+	}
+
+	got, err = GetComplianceHistory(context.TODO(), c, parent, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got[0].History) != 2 || got[0].History[0].EventName != "parent.4" {
+		t.Errorf("expected the 2 most recent events with maxHistory, got: %+v", got[0].History)
+	}
+}
+
+func TestEnqueueParentPolicyMapping(t *testing.T) {
+	type test struct {
+		name    string
+		event   *corev1.Event
+		wantReq bool
+	}
+
+	involved := corev1.ObjectReference{Kind: "Policy", Namespace: "default", Name: "parent"}
+
+	tests := []test{
+		{
+			name: "compliance event",
+			event: &corev1.Event{
+				InvolvedObject: involved,
+				Reason:         "policy: default/first",
+				Message:        "Compliant; all good",
+			},
+			wantReq: true,
+		}, {
+			name: "other event",
+			event: &corev1.Event{
+				InvolvedObject: involved,
+				Reason:         "PolicyPropagation",
+				Message:        "Policy default/parent was propagated",
+			},
+			wantReq: false,
+		},
+	}
+
+	for _, tc := range tests {
+		got := parentPolicyRequests(tc.event)
+
+		if tc.wantReq && (len(got) != 1 || got[0].Name != "parent" || got[0].Namespace != "default") {
+			t.Errorf("test '%v' expected a request for default/parent, got: %v", tc.name, got)
+		}
+
+		if !tc.wantReq && len(got) != 0 {
+			t.Errorf("test '%v' expected no requests, got: %v", tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events contains helpers for the compliance events which policies in
+// the policy framework send to their parent policies.
+package events
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// ComplianceEventRecorder records compliance events with
+// RecordAnnotatedComplianceEvent, but skips events which would be identical to
+// the last one emitted for the same policy, so that frequently reconciled
+// policies do not flood their parents with events. It is safe for concurrent
+// use.
+type ComplianceEventRecorder struct {
+	Recorder record.EventRecorder
+
+	// HeartbeatInterval is optional. If it is set, an event is emitted even when
+	// nothing has changed, if the last event for the policy is at least this old.
+	HeartbeatInterval time.Duration
+
+	mu   sync.Mutex
+	last map[types.NamespacedName]lastEvent
+
+	// now can be replaced in tests
+	now func() time.Time
+}
+
+// lastEvent is what the ComplianceEventRecorder remembers about the last event
+// it emitted for a policy.
+type lastEvent struct {
+	uid         types.UID
+	state       v1alpha1.ComplianceState
+	msg         string
+	annotations uint64
+	parents     string
+	time        time.Time
+}
+
+// Record emits a compliance event for the policy, unless the policy's
+// ComplianceState, the message, the event annotations (other than the
+// evaluation timestamp), and the parent policies are the same as the last event
+// emitted for it, and the HeartbeatInterval has not passed. It returns true if an event was
+// emitted, and passes through any error from recording the event, like
+// ErrNoParentPolicy.
+func (r *ComplianceEventRecorder) Record(policy v1alpha1.PolicyTyper, msg string) (bool, error) {
+	key := types.NamespacedName{Namespace: policy.GetNamespace(), Name: policy.GetName()}
+	current := lastEvent{
+		uid:         policy.GetUID(),
+		state:       policy.PolicyStatus().ComplianceState,
+		msg:         msg,
+		annotations: hashAnnotations(v1alpha1.ComplianceEventAnnotations(policy)),
+		parents:     parentsDigest(policy),
+		time:        r.currentTime(),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if prev, found := r.last[key]; found && !r.shouldEmit(prev, current) {
+		return false, nil
+	}
+
+	if err := v1alpha1.RecordAnnotatedComplianceEvent(r.Recorder, policy, msg); err != nil {
+		return false, err
+	}
+
+	if r.last == nil {
+		r.last = make(map[types.NamespacedName]lastEvent)
+	}
+
+	r.last[key] = current
+
+	return true, nil
+}
+
+// Forget removes what the recorder remembers about the policy, so that the next
+// event for a policy with the same name will always be emitted. It should be
+// called when the policy is deleted.
+func (r *ComplianceEventRecorder) Forget(namespace, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.last, types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+// shouldEmit returns true if the current event is different from the previous
+// one, or if the HeartbeatInterval has passed since the previous one.
+func (r *ComplianceEventRecorder) shouldEmit(prev, current lastEvent) bool {
+	if prev.uid != current.uid || prev.state != current.state || prev.msg != current.msg ||
+		prev.annotations != current.annotations || prev.parents != current.parents {
+		return true
+	}
+
+	return r.HeartbeatInterval > 0 && current.time.Sub(prev.time) >= r.HeartbeatInterval
+}
+
+func (r *ComplianceEventRecorder) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+
+	return time.Now()
+}
+
+// hashAnnotations returns a hash of the compliance event annotations, without
+// the evaluation timestamp, since it changes on every evaluation.
+func hashAnnotations(annotations map[string]string) uint64 {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		if key != v1alpha1.EventAnnotationEvaluationTimestamp {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	for _, key := range keys {
+		// The NUL separators can not appear in annotation keys, so different
+		// annotations can not produce the same input to the hash.
+		_, _ = h.Write([]byte(key + "\x00" + annotations[key] + "\x00"))
+	}
+
+	return h.Sum64()
+}
+
+// parentsDigest returns a string identifying the parent policies which the
+// events for the policy are sent to, so that a new parent receives an event even
+// when nothing else has changed.
+func parentsDigest(policy v1alpha1.PolicyTyper) string {
+	owners := v1alpha1.ParentPolicyOwners(policy)
+	parents := make([]string, len(owners))
+
+	for i, owner := range owners {
+		gv, _ := schema.ParseGroupVersion(owner.APIVersion)
+		parents[i] = strings.Join([]string{gv.Group, owner.Kind, owner.Name, string(owner.UID)}, "/")
+	}
+
+	sort.Strings(parents)
+
+	return strings.Join(parents, ",")
+}
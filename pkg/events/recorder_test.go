@@ -0,0 +1,111 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestComplianceEventRecorder(t *testing.T) {
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	r := &ComplianceEventRecorder{
+		Recorder:          record.NewFakeRecorder(10),
+		HeartbeatInterval: time.Hour,
+		now:               func() time.Time { return now },
+	}
+
+	policy := &v1alpha1.PolicyType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			UID:       "uid-1",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "policy.open-cluster-management.io/v1",
+				Kind:       "Policy",
+				Name:       "parent",
+			}},
+		},
+		Status: v1alpha1.PolicyTypeStatus{ComplianceState: v1alpha1.Compliant},
+	}
+
+	steps := []struct {
+		name     string
+		change   func()
+		wantSent bool
+	}{
+		{"first event", func() {}, true},
+		{"unchanged", func() { now = now.Add(time.Minute) }, false},
+		{"state changed", func() { policy.Status.ComplianceState = v1alpha1.NonCompliant }, true},
+		{"unchanged again", func() { now = now.Add(time.Minute) }, false},
+		{"heartbeat", func() { now = now.Add(time.Hour) }, true},
+		{"evaluated again", func() { policy.Status.LastEvaluated = metav1.NewTime(now) }, false},
+		{"severity changed", func() { policy.Spec.Severity = "high" }, true},
+		{"generation changed", func() { policy.Generation = 2 }, true},
+		{"related object added", func() {
+			policy.Status.RelatedObjects = append(policy.Status.RelatedObjects, v1alpha1.RelatedObject{
+				ComplianceState: v1alpha1.NonCompliant,
+			})
+		}, true},
+		{"unchanged annotations", func() { now = now.Add(time.Minute) }, false},
+		{"parent replaced", func() { policy.OwnerReferences[0].Name = "other-parent" }, true},
+		{"parent recreated", func() { policy.OwnerReferences[0].UID = "parent-uid-2" }, true},
+		{"other owner added", func() {
+			policy.OwnerReferences = append(policy.OwnerReferences, metav1.OwnerReference{
+				APIVersion: "argoproj.io/v1alpha1",
+				Kind:       "Application",
+				Name:       "gitops",
+			})
+		}, false},
+		{"unchanged parent", func() { now = now.Add(time.Minute) }, false},
+		{"policy recreated", func() { policy.UID = "uid-2" }, true},
+	}
+
+	for _, step := range steps {
+		step.change()
+
+		sent, err := r.Record(policy, "because test")
+		if err != nil {
+			t.Errorf("step '%v' unexpected error: %v", step.name, err)
+		}
+		if sent != step.wantSent {
+			t.Errorf("step '%v' expected sent: %v, got: %v", step.name, step.wantSent, sent)
+		}
+	}
+
+	r.Forget("default", "test")
+
+	if sent, _ := r.Record(policy, "because test"); !sent {
+		t.Error("expected an event after Forget")
+	}
+}
+
+func TestComplianceEventRecorderNoParent(t *testing.T) {
+	r := &ComplianceEventRecorder{Recorder: record.NewFakeRecorder(1)}
+
+	policy := &v1alpha1.PolicyType{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	if _, err := r.Record(policy, "msg"); err != v1alpha1.ErrNoParentPolicy {
+		t.Errorf("expected ErrNoParentPolicy, got: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics contains prometheus metrics about the compliance of policies
+// in the policy framework. The metrics are registered with controller-runtime's
+// metrics registry, so they are served on the manager's metrics endpoint.
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+var (
+	// ComplianceStatus reports the ComplianceState of each policy: 0 when it is
+	// Compliant, 1 when it is NonCompliant, and -1 when it is unknown.
+	ComplianceStatus = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "policy_compliance_status",
+			Help: "The compliance of the policy: 0 is Compliant, 1 is NonCompliant, and -1 is unknown",
+		},
+		[]string{"kind", "namespace", "name", "severity"},
+	)
+
+	// Evaluations counts how many times policies of each kind were evaluated.
+	Evaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "policy_evaluations_total",
+			Help: "The number of times policies of this kind were evaluated",
+		},
+		[]string{"kind"},
+	)
+
+	// EvaluationErrors counts how many evaluations of policies of each kind
+	// returned an error.
+	EvaluationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "policy_evaluation_errors_total",
+			Help: "The number of times evaluating policies of this kind returned an error",
+		},
+		[]string{"kind"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(ComplianceStatus, Evaluations, EvaluationErrors)
+}
+
+// policyKey identifies a policy's series in the ComplianceStatus gauge.
+type policyKey struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+var (
+	// severities remembers the severity label last used for each policy, so
+	// that the old series can be removed when the severity changes, or when
+	// the policy is deleted.
+	severities   = map[policyKey]string{}
+	severitiesMu sync.Mutex
+)
+
+// RecordCompliance sets the ComplianceStatus gauge for the policy, based on the
+// ComplianceState in its status. The kind is passed separately because typed
+// objects fetched with a client usually do not have their TypeMeta set.
+func RecordCompliance(kind string, policy v1alpha1.PolicyTyper) {
+	key := policyKey{kind: kind, namespace: policy.GetNamespace(), name: policy.GetName()}
+	severity := string(policy.PolicySpec().GetSeverity())
+
+	severitiesMu.Lock()
+	defer severitiesMu.Unlock()
+
+	if oldSeverity, found := severities[key]; found && oldSeverity != severity {
+		ComplianceStatus.DeleteLabelValues(kind, key.namespace, key.name, oldSeverity)
+	}
+
+	severities[key] = severity
+
+	ComplianceStatus.WithLabelValues(kind, key.namespace, key.name, severity).
+		Set(complianceValue(policy.PolicyStatus().ComplianceState))
+}
+
+// RecordEvaluation increments the Evaluations counter for the kind, and the
+// EvaluationErrors counter if the evaluation returned an error.
+func RecordEvaluation(kind string, err error) {
+	Evaluations.WithLabelValues(kind).Inc()
+
+	if err != nil {
+		EvaluationErrors.WithLabelValues(kind).Inc()
+	}
+}
+
+// DeleteCompliance removes the ComplianceStatus series for the policy, which
+// should be done when the policy is deleted.
+func DeleteCompliance(kind, namespace, name string) {
+	key := policyKey{kind: kind, namespace: namespace, name: name}
+
+	severitiesMu.Lock()
+	defer severitiesMu.Unlock()
+
+	if severity, found := severities[key]; found {
+		ComplianceStatus.DeleteLabelValues(kind, namespace, name, severity)
+		delete(severities, key)
+	}
+}
+
+// complianceValue returns the value of the ComplianceStatus gauge for the state.
+func complianceValue(state v1alpha1.ComplianceState) float64 {
+	switch state {
+	case v1alpha1.Compliant:
+		return 0
+	case v1alpha1.NonCompliant:
+		return 1
+	default:
+		return -1
+	}
+}
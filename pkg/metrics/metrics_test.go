@@ -0,0 +1,73 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestRecordCompliance(t *testing.T) {
+	policy := &v1alpha1.PolicyType{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha1.PolicyTypeSpec{Severity: "High"},
+		Status:     v1alpha1.PolicyTypeStatus{ComplianceState: v1alpha1.NonCompliant},
+	}
+
+	RecordCompliance("PolicyType", policy)
+
+	got := testutil.ToFloat64(ComplianceStatus.WithLabelValues("PolicyType", "default", "test", "high"))
+	if got != 1 {
+		t.Errorf("expected NonCompliant value 1, got: %v", got)
+	}
+
+	policy.Spec.Severity = "low"
+	policy.Status.ComplianceState = v1alpha1.Compliant
+	RecordCompliance("PolicyType", policy)
+
+	if count := testutil.CollectAndCount(ComplianceStatus); count != 1 {
+		t.Errorf("expected the old severity series to be removed, got %v series", count)
+	}
+
+	got = testutil.ToFloat64(ComplianceStatus.WithLabelValues("PolicyType", "default", "test", "low"))
+	if got != 0 {
+		t.Errorf("expected Compliant value 0, got: %v", got)
+	}
+
+	DeleteCompliance("PolicyType", "default", "test")
+
+	if count := testutil.CollectAndCount(ComplianceStatus); count != 0 {
+		t.Errorf("expected no series after delete, got %v series", count)
+	}
+}
+
+func TestRecordEvaluation(t *testing.T) {
+	RecordEvaluation("PolicyType", nil)
+	RecordEvaluation("PolicyType", errors.New("boom"))
+
+	if got := testutil.ToFloat64(Evaluations.WithLabelValues("PolicyType")); got != 2 {
+		t.Errorf("expected 2 evaluations, got: %v", got)
+	}
+	if got := testutil.ToFloat64(EvaluationErrors.WithLabelValues("PolicyType")); got != 1 {
+		t.Errorf("expected 1 evaluation error, got: %v", got)
+	}
+}
@@ -0,0 +1,422 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/pkg/evaluator"
+	"github.com/JustinKuli/policy-framework/pkg/reconciler"
+)
+
+// policyRules are the fields in a policy's spec which policyctl can evaluate,
+// in addition to the PolicyTypeSpec fields which select what they apply to.
+type policyRules struct {
+	ObjectTemplates []evaluator.ObjectTemplate `json:"objectTemplates,omitempty"`
+	CELRules        []evaluator.CELRule        `json:"celRules,omitempty"`
+	RegoRules       []evaluator.RegoRule       `json:"regoRules,omitempty"`
+}
+
+// kinds returns the kinds of objects that the rules check.
+func (rules policyRules) kinds() []schema.GroupVersionKind {
+	kinds := make([]schema.GroupVersionKind, 0)
+
+	for _, tmpl := range rules.ObjectTemplates {
+		if tmpl.ObjectDefinition != nil {
+			kinds = append(kinds, tmpl.ObjectDefinition.GroupVersionKind())
+		}
+	}
+
+	for _, rule := range rules.CELRules {
+		kinds = append(kinds, schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind))
+	}
+
+	for _, rule := range rules.RegoRules {
+		kinds = append(kinds, schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind))
+	}
+
+	return kinds
+}
+
+// evaluatedPolicy is the output of the evaluate command for one policy.
+type evaluatedPolicy struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	Metadata   v1alpha1.ObjectMetadata   `json:"metadata"`
+	Status     v1alpha1.PolicyTypeStatus `json:"status"`
+}
+
+// runEvaluate implements the evaluate command.
+func runEvaluate(args []string, stdout, stderr io.Writer) int {
+	var policyPaths, manifestPaths pathList
+
+	flags := flag.NewFlagSet("evaluate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&policyPaths, "policies", "A file or directory of policies to evaluate. Can be given more than once.")
+	flags.Var(&manifestPaths, "manifests",
+		"A file or directory of the objects on the cluster. Can be given more than once.")
+	output := flags.String("o", "yaml", "The output format, either yaml or json.")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: policyctl evaluate --policies <path> --manifests <path> [-o yaml|json]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Evaluates the policies against an in-memory cluster holding the objects in the")
+		fmt.Fprintln(stderr, "manifests, and prints the status of each policy. The objectTemplates,")
+		fmt.Fprintln(stderr, "celRules, and regoRules in each policy's spec are evaluated; nothing is")
+		fmt.Fprintln(stderr, "remediated, whatever the remediationAction is. The exit code is 2 when a")
+		fmt.Fprintln(stderr, "policy is not compliant.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		return ExitError
+	}
+
+	if len(policyPaths) == 0 {
+		fmt.Fprintln(stderr, "at least one --policies path is required")
+
+		return ExitError
+	}
+
+	if *output != "yaml" && *output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+
+		return ExitError
+	}
+
+	policies, err := readManifests(policyPaths...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	manifests, err := readManifests(manifestPaths...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	results, err := evaluatePolicies(context.Background(), policies, manifests)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	exitCode := ExitOK
+
+	for _, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(stderr, "%v %v: %v\n", result.Kind, objectKey(result.Metadata), result.err)
+
+			exitCode = ExitError
+		} else if result.Status.ComplianceState != v1alpha1.Compliant && exitCode == ExitOK {
+			exitCode = ExitViolations
+		}
+	}
+
+	if err := writeEvaluated(stdout, *output, results); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	return exitCode
+}
+
+// evaluationResult is the status of a policy after it was evaluated, and the
+// error from the evaluation, if there was one.
+type evaluationResult struct {
+	evaluatedPolicy
+	err error
+}
+
+// evaluatePolicies evaluates each of the policies against a fake cluster which
+// holds the manifests, with the same reconciler that policy controllers use.
+func evaluatePolicies(
+	ctx context.Context, policies, manifests []*unstructured.Unstructured,
+) ([]evaluationResult, error) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	// The framework's PolicyType stands in for every kind of policy, since
+	// only the PolicyTypeSpec and PolicyTypeStatus fields are needed.
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	objs := make([]client.Object, 0, len(manifests)+len(policies))
+	for _, obj := range manifests {
+		objs = append(objs, obj)
+	}
+
+	kinds := []schema.GroupVersionKind{v1alpha1.GroupVersion.WithKind("PolicyType")}
+	rules := make(map[types.NamespacedName]policyRules, len(policies))
+	results := make([]evaluationResult, 0, len(policies))
+
+	for _, obj := range policies {
+		policy, specRules, err := parsePolicy(obj)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %v: %w", obj.GetKind(), obj.GetName(), err)
+		}
+
+		key := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+		if _, found := rules[key]; found {
+			return nil, fmt.Errorf("more than one policy is named %v", key)
+		}
+
+		rules[key] = specRules
+		kinds = append(kinds, specRules.kinds()...)
+		objs = append(objs, policy)
+
+		results = append(results, evaluationResult{evaluatedPolicy: evaluatedPolicy{
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Metadata:   v1alpha1.ObjectMetadata{Name: policy.Name, Namespace: policy.Namespace},
+		}})
+	}
+
+	c, err := newFakeCluster(scheme, objs, kinds...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		key := types.NamespacedName{Namespace: result.Metadata.Namespace, Name: result.Metadata.Name}
+
+		r := &reconciler.PolicyReconciler{
+			Client:   c,
+			Recorder: &record.FakeRecorder{},
+			NewPolicy: func() v1alpha1.PolicyTyper {
+				return &v1alpha1.PolicyType{}
+			},
+			Evaluate: evaluateRules(c, rules[key]),
+		}
+
+		_, results[i].err = r.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+
+		policy := &v1alpha1.PolicyType{}
+		if err := c.Get(ctx, key, policy); err != nil {
+			return nil, err
+		}
+
+		results[i].Status = policy.Status
+	}
+
+	return results, nil
+}
+
+// parsePolicy returns a PolicyType with the metadata and the PolicyTypeSpec
+// fields of the policy, and the rules in its spec.
+func parsePolicy(obj *unstructured.Unstructured) (*v1alpha1.PolicyType, policyRules, error) {
+	rules := policyRules{}
+
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return nil, rules, err
+	}
+
+	if !found {
+		return nil, rules, errors.New("the policy has no spec")
+	}
+
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, rules, err
+	}
+
+	policy := &v1alpha1.PolicyType{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        obj.GetName(),
+			Namespace:   obj.GetNamespace(),
+			Labels:      obj.GetLabels(),
+			Annotations: obj.GetAnnotations(),
+			Generation:  1,
+		},
+	}
+
+	if policy.Namespace == "" {
+		policy.Namespace = defaultNamespace
+	}
+
+	if err := json.Unmarshal(specJSON, &policy.Spec); err != nil {
+		return nil, rules, err
+	}
+
+	if err := json.Unmarshal(specJSON, &rules); err != nil {
+		return nil, rules, err
+	}
+
+	if len(rules.ObjectTemplates)+len(rules.CELRules)+len(rules.RegoRules) == 0 {
+		return nil, rules, errors.New("the policy has no objectTemplates, celRules, or regoRules")
+	}
+
+	return policy, rules, nil
+}
+
+// evaluateRules returns an EvaluateFunc which runs the evaluator for each type
+// of rule in the policy, and combines their results. The policy is
+// NonCompliant if any evaluator found it NonCompliant, otherwise it is
+// UnknownCompliancy if any evaluator could not tell, and Compliant if all of
+// them found it Compliant.
+func evaluateRules(c client.Client, rules policyRules) reconciler.EvaluateFunc {
+	objectTemplates := &evaluator.ObjectTemplateEvaluator{Client: c}
+	celRules := &evaluator.CELEvaluator{Client: c}
+	regoRules := &evaluator.RegoEvaluator{Client: c}
+
+	type evaluation struct {
+		state  v1alpha1.ComplianceState
+		reason string
+		msg    string
+	}
+
+	return func(ctx context.Context, policy v1alpha1.PolicyTyper) (
+		v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+	) {
+		spec := policy.PolicySpec()
+		related := make([]v1alpha1.RelatedObject, 0)
+		evaluations := make([]evaluation, 0, 3)
+
+		record := func(state v1alpha1.ComplianceState, objs []v1alpha1.RelatedObject, reason, msg string) {
+			related = append(related, objs...)
+			evaluations = append(evaluations, evaluation{state: state, reason: reason, msg: msg})
+		}
+
+		if len(rules.ObjectTemplates) != 0 {
+			state, objs, reason, msg, err := objectTemplates.Evaluate(ctx, spec, rules.ObjectTemplates...)
+			if err != nil {
+				return v1alpha1.UnknownCompliancy, nil, "", "", err
+			}
+
+			record(state, objs, reason, msg)
+		}
+
+		if len(rules.CELRules) != 0 {
+			state, objs, reason, msg, err := celRules.Evaluate(ctx, spec, rules.CELRules...)
+			if err != nil {
+				return v1alpha1.UnknownCompliancy, nil, "", "", err
+			}
+
+			record(state, objs, reason, msg)
+		}
+
+		if len(rules.RegoRules) != 0 {
+			state, objs, reason, msg, err := regoRules.Evaluate(ctx, spec, rules.RegoRules...)
+			if err != nil {
+				return v1alpha1.UnknownCompliancy, nil, "", "", err
+			}
+
+			record(state, objs, reason, msg)
+		}
+
+		state := v1alpha1.Compliant
+
+		for _, eval := range evaluations {
+			if eval.state == v1alpha1.NonCompliant {
+				state = v1alpha1.NonCompliant
+
+				break
+			}
+
+			if eval.state != v1alpha1.Compliant {
+				state = v1alpha1.UnknownCompliancy
+			}
+		}
+
+		reason := ""
+		msgs := make([]string, 0, len(evaluations))
+
+		for _, eval := range evaluations {
+			if eval.state != state {
+				continue
+			}
+
+			if reason == "" {
+				reason = eval.reason
+			}
+
+			msgs = append(msgs, eval.msg)
+		}
+
+		return state, related, reason, strings.Join(msgs, "; "), nil
+	}
+}
+
+// writeEvaluated writes the evaluated policies in the output format: YAML
+// documents, or a JSON array.
+func writeEvaluated(w io.Writer, output string, results []evaluationResult) error {
+	policies := make([]evaluatedPolicy, len(results))
+	for i, result := range results {
+		policies[i] = result.evaluatedPolicy
+	}
+
+	if output == "json" {
+		out, err := json.MarshalIndent(policies, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(out))
+
+		return err
+	}
+
+	for _, policy := range policies {
+		out, err := yaml.Marshal(policy)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// objectKey returns the namespace and name of the object, separated by a slash.
+func objectKey(metadata v1alpha1.ObjectMetadata) string {
+	if metadata.Namespace == "" {
+		return metadata.Name
+	}
+
+	return metadata.Namespace + "/" + metadata.Name
+}
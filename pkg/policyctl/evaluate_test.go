@@ -0,0 +1,139 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestEvaluate(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	code := Run([]string{
+		"evaluate", "--policies", "testdata/policies.yaml", "--manifests", "testdata/manifests", "-o", "json",
+	}, stdout, stderr)
+	if code != ExitViolations {
+		t.Fatalf("expected exit code %v, got: %v, stderr: %v", ExitViolations, code, stderr)
+	}
+
+	got := make([]evaluatedPolicy, 0)
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	type test struct {
+		kind        string
+		wantState   v1alpha1.ComplianceState
+		wantMsg     string
+		wantRelated int
+	}
+
+	tests := []test{
+		{
+			kind:        "ConfigurationPolicy",
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "ConfigMap policies/settings not found",
+			wantRelated: 2,
+		}, {
+			kind:        "CELPolicy",
+			wantState:   v1alpha1.Compliant,
+			wantMsg:     "all objects satisfy the rules",
+			wantRelated: 2,
+		}, {
+			kind:        "RegoPolicy",
+			wantState:   v1alpha1.NonCompliant,
+			wantMsg:     "Widget red: missing the owner label",
+			wantRelated: 2,
+		},
+	}
+
+	if len(got) != len(tests) {
+		t.Fatalf("expected %v policies, got: %v", len(tests), got)
+	}
+
+	for i, tc := range tests {
+		policy := got[i]
+		if policy.Kind != tc.kind {
+			t.Errorf("test '%v' expected kind: %v, got: %v", tc.kind, tc.kind, policy.Kind)
+		}
+
+		if policy.Status.ComplianceState != tc.wantState {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.kind, tc.wantState, policy.Status.ComplianceState)
+		}
+
+		if len(policy.Status.RelatedObjects) != tc.wantRelated {
+			t.Errorf("test '%v' expected %v related objects, got: %v",
+				tc.kind, tc.wantRelated, policy.Status.RelatedObjects)
+		}
+
+		if policy.Status.ObservedGeneration != 1 {
+			t.Errorf("test '%v' expected observedGeneration 1, got: %v", tc.kind, policy.Status.ObservedGeneration)
+		}
+
+		cond := meta.FindStatusCondition(policy.Status.Conditions, v1alpha1.ComplianceConditionType)
+		if cond == nil || cond.Message != tc.wantMsg {
+			t.Errorf("test '%v' expected message: %v, got: %+v", tc.kind, tc.wantMsg, cond)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	type test struct {
+		name      string
+		args      []string
+		wantError string
+	}
+
+	tests := []test{
+		{
+			name:      "no policies",
+			args:      []string{"evaluate", "--manifests", "testdata/manifests"},
+			wantError: "at least one --policies path is required",
+		}, {
+			name:      "bad output format",
+			args:      []string{"evaluate", "--policies", "testdata/policies.yaml", "-o", "xml"},
+			wantError: `unknown output format "xml"`,
+		}, {
+			name:      "missing file",
+			args:      []string{"evaluate", "--policies", "testdata/missing.yaml"},
+			wantError: "no such file or directory",
+		}, {
+			name:      "policy without rules",
+			args:      []string{"evaluate", "--policies", "testdata/manifests/configmaps.yaml"},
+			wantError: "the policy has no spec",
+		},
+	}
+
+	for _, tc := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := Run(tc.args, stdout, stderr); code != ExitError {
+			t.Errorf("test '%v' expected exit code %v, got: %v", tc.name, ExitError, code)
+		}
+
+		if !strings.Contains(stderr.String(), tc.wantError) {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantError, stderr)
+		}
+	}
+}
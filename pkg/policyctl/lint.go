@@ -0,0 +1,465 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// Severities of lint findings.
+const (
+	// LintError means the policy would be rejected by the cluster, or could not
+	// be evaluated.
+	LintError string = "error"
+	// LintWarning means the policy is valid, but is probably not doing what
+	// was intended.
+	LintWarning string = "warning"
+)
+
+// Rules which lint findings come from.
+const (
+	// RuleSchema findings come from the schema in the CRD of the policy, or of
+	// the PolicyType when the CRD of the policy is not available.
+	RuleSchema string = "schema"
+	// RuleValidation findings come from the PolicyTypeSpec validation, which
+	// is also done by the framework's validating webhook.
+	RuleValidation string = "validation"
+	// RuleSelector findings are patterns or label requirements in the
+	// NamespaceSelector which can never select a namespace.
+	RuleSelector string = "selector"
+	// RuleRemediation findings are remediationActions which the policy can not
+	// carry out. They are errors when the policy type does not support the
+	// remediationAction, since the validating webhook rejects those policies.
+	RuleRemediation string = "remediation"
+)
+
+// Scheme holds the policy types which policyctl knows about. The lint command
+// rejects a remediationAction which is not supported by the policy's type when
+// the type implements v1alpha1.RemediationActionSupporter, like the framework's
+// validating webhook does. Programs which embed policyctl can register their
+// policy types in it. Kinds which are not registered can be treated as
+// inform-only with the --inform-only-kind flag.
+var Scheme = runtime.NewScheme()
+
+// nonPolicyKinds are the kinds in the policy group which are not policies.
+var nonPolicyKinds = map[string]bool{
+	"PlacementBinding": true,
+	"PolicyAutomation": true,
+	"PolicySet":        true,
+}
+
+// lintFinding is a problem found in a policy by the lint command.
+type lintFinding struct {
+	File      string `json:"file"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Severity  string `json:"severity"`
+	Rule      string `json:"rule"`
+	Field     string `json:"field,omitempty"`
+	Message   string `json:"message"`
+}
+
+// lintReport is the output of the lint command.
+type lintReport struct {
+	Policies int           `json:"policies"`
+	Errors   int           `json:"errors"`
+	Warnings int           `json:"warnings"`
+	Findings []lintFinding `json:"findings"`
+}
+
+// runLint implements the lint command.
+func runLint(args []string, stdout, stderr io.Writer) int {
+	var crdPaths, informOnly pathList
+
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&crdPaths, "crds",
+		"A file or directory of CRDs for the policy kinds, to check policies against. Can be given more than once.")
+	flags.Var(&informOnly, "inform-only-kind",
+		"A policy kind which only supports inform, like Kind.group. Can be given more than once.")
+	output := flags.String("o", "text", "The output format, either text or json.")
+	strict := flags.Bool("strict", false, "Exit with code 2 when there are warnings, not only errors.")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: policyctl lint [--crds <path>] [-o text|json] <path>...")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Checks the policies in the files at the paths against the CRD schemas, and for")
+		fmt.Fprintln(stderr, "mistakes like namespace patterns which can never select anything. Policies in")
+		fmt.Fprintln(stderr, "the policy-templates of a Policy are also checked, and other objects are")
+		fmt.Fprintln(stderr, "skipped. The exit code is 2 when an error is found.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		return ExitError
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "at least one path to lint is required")
+
+		return ExitError
+	}
+
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+
+		return ExitError
+	}
+
+	l, err := newLinter(crdPaths, informOnly)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	report := lintReport{Findings: make([]lintFinding, 0)}
+
+	err = walkManifests(flags.Args(), func(file string, objs []*unstructured.Unstructured) error {
+		for _, obj := range objs {
+			for _, policy := range policiesIn(obj) {
+				report.Policies++
+
+				for _, finding := range l.lint(policy.obj, policy.path) {
+					finding.File = file
+					report.Findings = append(report.Findings, finding)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	for _, finding := range report.Findings {
+		if finding.Severity == LintError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	if err := writeLintReport(stdout, *output, report); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	if report.Errors != 0 || (*strict && report.Warnings != 0) {
+		return ExitViolations
+	}
+
+	return ExitOK
+}
+
+// linter checks policies for problems.
+type linter struct {
+	schemas    *schemaValidators
+	informOnly map[schema.GroupKind]bool
+}
+
+// newLinter returns a linter which uses the FrameworkCRDs and the CRDs at the
+// paths, and which treats the given kinds as inform-only when they are not
+// registered in the Scheme.
+func newLinter(crdPaths, informOnly []string) (*linter, error) {
+	crds := make([]*unstructured.Unstructured, 0)
+
+	if FrameworkCRDs != nil {
+		frameworkCRDs, err := readCRDs(FrameworkCRDs)
+		if err != nil {
+			return nil, err
+		}
+
+		crds = append(crds, frameworkCRDs...)
+	}
+
+	extraCRDs, err := readManifests(crdPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := newSchemaValidators(append(crds, extraCRDs...))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{schemas: schemas, informOnly: make(map[schema.GroupKind]bool)}
+
+	for _, kind := range informOnly {
+		l.informOnly[schema.ParseGroupKind(kind)] = true
+	}
+
+	return l, nil
+}
+
+// embeddedPolicy is a policy found in a manifest, and the path to it within the
+// manifest, which is nil when the manifest is the policy itself.
+type embeddedPolicy struct {
+	obj  *unstructured.Unstructured
+	path *field.Path
+}
+
+// policiesIn returns the policies in the object: the object itself if it is a
+// policy, or the policies in its policy-templates if it is a parent policy.
+func policiesIn(obj *unstructured.Unstructured) []embeddedPolicy {
+	if !isPolicy(obj) {
+		return nil
+	}
+
+	if !isParentPolicyKind(obj.GroupVersionKind().GroupKind()) {
+		return []embeddedPolicy{{obj: obj}}
+	}
+
+	templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "policy-templates")
+	policies := make([]embeddedPolicy, 0, len(templates))
+
+	for i, tmpl := range templates {
+		tmplMap, _ := tmpl.(map[string]interface{})
+
+		def, ok := tmplMap["objectDefinition"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		policy := &unstructured.Unstructured{Object: def}
+		if !isPolicy(policy) || isParentPolicyKind(policy.GroupVersionKind().GroupKind()) {
+			continue
+		}
+
+		policies = append(policies, embeddedPolicy{
+			obj:  policy,
+			path: field.NewPath("spec", "policy-templates").Index(i).Child("objectDefinition"),
+		})
+	}
+
+	return policies
+}
+
+// isPolicy returns true if the object is in the policy group, and is not one
+// of the nonPolicyKinds.
+func isPolicy(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+
+	return gvk.Group == v1alpha1.GroupVersion.Group && !nonPolicyKinds[gvk.Kind]
+}
+
+// isParentPolicyKind returns true if the kind is in ParentPolicyKinds.
+func isParentPolicyKind(gk schema.GroupKind) bool {
+	for _, parent := range v1alpha1.ParentPolicyKinds {
+		if parent == gk {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lint returns the problems in the policy. The path is where the policy is in
+// its manifest, and is added to the front of the fields in the findings.
+func (l *linter) lint(policy *unstructured.Unstructured, path *field.Path) []lintFinding {
+	findings := make([]lintFinding, 0)
+	add := func(severity, rule string, errs field.ErrorList) {
+		for _, err := range errs {
+			fieldPath := err.Field
+			if path != nil {
+				fieldPath = path.String() + "." + fieldPath
+			}
+
+			findings = append(findings, lintFinding{
+				Kind:      policy.GetKind(),
+				Namespace: policy.GetNamespace(),
+				Name:      policy.GetName(),
+				Severity:  severity,
+				Rule:      rule,
+				Field:     fieldPath,
+				Message:   err.ErrorBody(),
+			})
+		}
+	}
+
+	schemaErrs := l.schemas.validate(policy)
+	add(LintError, RuleSchema, schemaErrs)
+
+	specPath := field.NewPath("spec")
+
+	spec, rules, err := decodeSpec(policy)
+	if err != nil {
+		if len(schemaErrs) == 0 {
+			add(LintError, RuleSchema, field.ErrorList{field.Invalid(specPath, nil, err.Error())})
+		}
+
+		return findings
+	}
+
+	// Problems that the schema already reported are not repeated
+	reported := make(map[string]bool, len(schemaErrs))
+	for _, err := range schemaErrs {
+		reported[normalizeField(err.Field)] = true
+	}
+
+	validationErrs := field.ErrorList{}
+
+	for _, err := range spec.Validate(specPath) {
+		if !reported[normalizeField(err.Field)] {
+			validationErrs = append(validationErrs, err)
+		}
+	}
+
+	add(LintError, RuleValidation, validationErrs)
+	add(LintWarning, RuleSelector, spec.Warnings(specPath))
+	add(LintError, RuleRemediation, l.remediationErrors(policy, spec, specPath))
+	add(LintWarning, RuleRemediation, remediationWarnings(spec, rules))
+
+	return findings
+}
+
+// mapKeyPattern matches the map keys in a field path, like "[app]", but not
+// list indexes, like "[0]".
+var mapKeyPattern = regexp.MustCompile(`\[([^\]]*[^\]0-9][^\]]*)\]`)
+
+// normalizeField returns the field path with map keys written like fields, the
+// way the schema validation writes them, so that paths can be compared.
+func normalizeField(path string) string {
+	return mapKeyPattern.ReplaceAllString(path, ".$1")
+}
+
+// decodeSpec returns the PolicyTypeSpec fields of the policy, and the rules in
+// its spec which policyctl can evaluate.
+func decodeSpec(policy *unstructured.Unstructured) (v1alpha1.PolicyTypeSpec, policyRules, error) {
+	spec := v1alpha1.PolicyTypeSpec{}
+	rules := policyRules{}
+
+	specJSON, err := json.Marshal(policy.Object["spec"])
+	if err != nil {
+		return spec, rules, err
+	}
+
+	if err := json.Unmarshal(specJSON, &spec); err != nil {
+		return spec, rules, err
+	}
+
+	if err := json.Unmarshal(specJSON, &rules); err != nil {
+		return spec, rules, err
+	}
+
+	return spec, rules, nil
+}
+
+// remediationErrors reports a remediationAction which the policy's type does
+// not support, with the same check as the validating webhook. The supported
+// actions come from the type in the Scheme when it implements
+// v1alpha1.RemediationActionSupporter, and otherwise only inform is supported
+// by the kinds given with --inform-only-kind.
+func (l *linter) remediationErrors(
+	policy *unstructured.Unstructured, spec v1alpha1.PolicyTypeSpec, specPath *field.Path,
+) field.ErrorList {
+	gvk := policy.GroupVersionKind()
+
+	if Scheme.Recognizes(gvk) {
+		obj, err := Scheme.New(gvk)
+		if err == nil {
+			if supporter, ok := obj.(v1alpha1.RemediationActionSupporter); ok {
+				return spec.ValidateRemediationAction(specPath, supporter.SupportedRemediationActions())
+			}
+		}
+	}
+
+	if l.informOnly[gvk.GroupKind()] {
+		return spec.ValidateRemediationAction(specPath, []v1alpha1.RemediationAction{v1alpha1.RemediationInform})
+	}
+
+	return nil
+}
+
+// remediationWarnings reports a remediationAction of enforce or dryrun on a
+// policy which only has rules which can not be remediated.
+func remediationWarnings(spec v1alpha1.PolicyTypeSpec, rules policyRules) field.ErrorList {
+	if !spec.IsEnforce() && !spec.IsDryRun() {
+		return nil
+	}
+
+	if len(rules.ObjectTemplates) == 0 && len(rules.CELRules)+len(rules.RegoRules) != 0 {
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "remediationAction"), spec.RemediationAction,
+			"celRules and regoRules can not be enforced, so the policy only informs")}
+	}
+
+	return nil
+}
+
+// writeLintReport writes the report in the output format: one line for each
+// finding followed by a summary, or a JSON object.
+func writeLintReport(w io.Writer, output string, report lintReport) error {
+	if output == "json" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(out))
+
+		return err
+	}
+
+	for _, f := range report.Findings {
+		_, err := fmt.Fprintf(w, "%v: %v %v: %v: %v: %v [%v]\n", f.File, f.Kind,
+			objectKey(v1alpha1.ObjectMetadata{Name: f.Name, Namespace: f.Namespace}),
+			f.Severity, f.Field, f.Message, f.Rule)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%v, %v in %v\n", plural(report.Errors, "error"), plural(report.Warnings, "warning"),
+		plural(report.Policies, "policy"))
+
+	return err
+}
+
+// plural returns the count followed by the noun, in plural form when needed.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%v %vies", count, strings.TrimSuffix(noun, "y"))
+	}
+
+	return fmt.Sprintf("%v %vs", count, noun)
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// informOnlyPolicy is a policy type which only supports inform.
+type informOnlyPolicy struct {
+	v1alpha1.PolicyType
+}
+
+func (p *informOnlyPolicy) SupportedRemediationActions() []v1alpha1.RemediationAction {
+	return []v1alpha1.RemediationAction{v1alpha1.RemediationInform}
+}
+
+func (p *informOnlyPolicy) DeepCopyObject() runtime.Object {
+	return &informOnlyPolicy{PolicyType: *p.PolicyType.DeepCopy()}
+}
+
+func TestLint(t *testing.T) {
+	FrameworkCRDs = os.DirFS("../../config/crd/bases")
+	defer func() { FrameworkCRDs = nil }()
+
+	scheme := Scheme
+	defer func() { Scheme = scheme }()
+
+	Scheme = runtime.NewScheme()
+	Scheme.AddKnownTypeWithName(schema.GroupVersionKind{
+		Group: v1alpha1.GroupVersion.Group, Version: "v1", Kind: "CertificatePolicy",
+	}, &informOnlyPolicy{})
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	code := Run([]string{
+		"lint", "-o", "json", "--crds", "../../test/mockpolicy/config/crd/bases", "testdata/lint",
+	}, stdout, stderr)
+	if code != ExitViolations {
+		t.Fatalf("expected exit code %v, got: %v, stderr: %v", ExitViolations, code, stderr)
+	}
+
+	report := lintReport{}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Policies != 5 || report.Errors != 5 || report.Warnings != 4 {
+		t.Errorf("expected 5 policies, 5 errors and 4 warnings, got: %+v", report)
+	}
+
+	got := make([]string, len(report.Findings))
+	for i, f := range report.Findings {
+		got[i] = strings.Join([]string{f.Name, f.Severity, f.Rule, f.Field}, " ")
+	}
+
+	want := []string{
+		"bad-schema error schema spec.labelSelector.app",
+		"bad-schema error schema spec.severity",
+		"bad-schema error validation spec.namespaceSelector.include[0]",
+		"selectors warning selector spec.namespaceSelector.include[1]",
+		"selectors warning selector spec.namespaceSelector.include[2]",
+		"selectors warning selector spec.namespaceSelector",
+		"selectors error remediation spec.remediationAction",
+		"replicas warning remediation spec.policy-templates[0].objectDefinition.spec.remediationAction",
+		"mock error schema spec.foo",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected findings: %v, got: %v", want, got)
+	}
+
+	for _, f := range report.Findings {
+		if f.File == "" || f.Message == "" {
+			t.Errorf("expected a file and message in finding: %+v", f)
+		}
+	}
+}
+
+func TestLintText(t *testing.T) {
+	informOnly := "ConfigurationPolicy.policy.open-cluster-management.io"
+
+	type test struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout []string
+	}
+
+	tests := []test{
+		{
+			name:       "no findings",
+			args:       []string{"lint", "testdata/lint/good.yaml"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"0 errors, 0 warnings in 1 policy\n"},
+		}, {
+			name:     "inform-only kind from a flag",
+			args:     []string{"lint", "--inform-only-kind", informOnly, "testdata/lint/good.yaml"},
+			wantCode: ExitViolations,
+			wantStdout: []string{
+				"testdata/lint/good.yaml: ConfigurationPolicy policies/good: error: spec.remediationAction: " +
+					`Invalid value: "enforce": this policy type only supports these remediation actions: [inform] ` +
+					"[remediation]",
+				"1 error, 0 warnings in 1 policy\n",
+			},
+		}, {
+			name:       "warnings",
+			args:       []string{"lint", "testdata/lint/cel.yaml"},
+			wantCode:   ExitOK,
+			wantStdout: []string{"0 errors, 1 warning in 1 policy\n"},
+		}, {
+			name:       "warnings with strict",
+			args:       []string{"lint", "--strict", "testdata/lint/cel.yaml"},
+			wantCode:   ExitViolations,
+			wantStdout: []string{"0 errors, 1 warning in 1 policy\n"},
+		},
+	}
+
+	for _, tc := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := Run(tc.args, stdout, stderr); code != tc.wantCode {
+			t.Errorf("test '%v' expected exit code %v, got: %v, stderr: %v", tc.name, tc.wantCode, code, stderr)
+		}
+
+		for _, want := range tc.wantStdout {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("test '%v' expected stdout to contain: %v, got: %v", tc.name, want, stdout)
+			}
+		}
+	}
+}
+
+func TestNormalizeField(t *testing.T) {
+	tests := map[string]string{
+		"spec.labelSelector[app]":            "spec.labelSelector.app",
+		"spec.namespaceSelector.include[0]":  "spec.namespaceSelector.include[0]",
+		"spec.items[10].labels[app.io/name]": "spec.items[10].labels.app.io/name",
+	}
+
+	for input, want := range tests {
+		if got := normalizeField(input); got != want {
+			t.Errorf("test '%v' expected: %v, got: %v", input, want, got)
+		}
+	}
+}
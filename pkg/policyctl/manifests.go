@@ -0,0 +1,265 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// defaultNamespace is used for namespaced objects which do not have one, like
+// kubectl does.
+const defaultNamespace = "default"
+
+// clusterScopedKinds are the built-in kinds which are not namespaced. Custom
+// resources are cluster-scoped when the manifests include their
+// CustomResourceDefinition with the Cluster scope; all other kinds are treated
+// as namespaced.
+var clusterScopedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Namespace"}:        true,
+	{Group: "", Kind: "Node"}:             true,
+	{Group: "", Kind: "PersistentVolume"}: true,
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"}:   true,
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"}: true,
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:               true,
+	{Group: "apiregistration.k8s.io", Kind: "APIService"}:                           true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"}:                       true,
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"}:                true,
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"}:                             true,
+	{Group: "storage.k8s.io", Kind: "CSIDriver"}:                                    true,
+	{Group: "storage.k8s.io", Kind: "StorageClass"}:                                 true,
+}
+
+// readManifests reads the objects in the files at the paths. Directories are
+// read recursively, using the files ending in .yaml, .yml, or .json. Files may
+// contain several YAML documents, and objects of kind List are expanded.
+func readManifests(paths ...string) ([]*unstructured.Unstructured, error) {
+	objs := make([]*unstructured.Unstructured, 0)
+
+	err := walkManifests(paths, func(_ string, fileObjs []*unstructured.Unstructured) error {
+		objs = append(objs, fileObjs...)
+
+		return nil
+	})
+
+	return objs, err
+}
+
+// walkManifests reads the files at the paths like readManifests, and calls the
+// function with the objects in each file.
+func walkManifests(paths []string, fn func(file string, objs []*unstructured.Unstructured) error) error {
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || (file != path && !isManifestFile(file)) {
+				return nil
+			}
+
+			fileObjs, err := readManifestFile(file)
+			if err != nil {
+				return fmt.Errorf("unable to read %v: %w", file, err)
+			}
+
+			return fn(file, fileObjs)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isManifestFile returns true if the file has an extension used for manifests.
+func isManifestFile(file string) bool {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// readManifestFile returns the objects in the file.
+func readManifestFile(file string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return decodeManifests(f)
+}
+
+// decodeManifests returns the objects in each YAML or JSON document read from
+// the reader, skipping empty documents.
+func decodeManifests(r io.Reader) ([]*unstructured.Unstructured, error) {
+	objs := make([]*unstructured.Unstructured, 0)
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+
+	for {
+		doc := map[string]interface{}{}
+
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(doc) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{Object: doc}
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("document %v is missing an apiVersion or kind", len(objs)+1)
+		}
+
+		if !obj.IsList() {
+			objs = append(objs, obj)
+
+			continue
+		}
+
+		err = obj.EachListItem(func(item runtime.Object) error {
+			u, ok := item.(*unstructured.Unstructured)
+			if !ok || u.GetAPIVersion() == "" || u.GetKind() == "" {
+				return fmt.Errorf("a %v item is missing an apiVersion or kind", obj.GetKind())
+			}
+
+			objs = append(objs, u)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// newFakeCluster returns a client for an in-memory cluster which holds the
+// objects. Namespaced objects without a namespace are put in the default
+// namespace, and a Namespace is created for each namespace used by an object,
+// since they would exist on a real cluster. The client's RESTMapper knows the
+// kinds of the objects, and the additional kinds, so that the evaluators can
+// find out whether each kind is namespaced.
+func newFakeCluster(
+	scheme *runtime.Scheme, objs []client.Object, kinds ...schema.GroupVersionKind,
+) (client.Client, error) {
+	clusterScoped := make(map[schema.GroupKind]bool, len(clusterScopedKinds))
+	for gk := range clusterScopedKinds {
+		clusterScoped[gk] = true
+	}
+
+	for _, obj := range objs {
+		if crd, ok := obj.(*unstructured.Unstructured); ok && crd.GetKind() == "CustomResourceDefinition" {
+			group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+			scope, _, _ := unstructured.NestedString(crd.Object, "spec", "scope")
+			clusterScoped[schema.GroupKind{Group: group, Kind: kind}] = scope == "Cluster"
+		}
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	addKind := func(gvk schema.GroupVersionKind) {
+		scope := meta.RESTScopeNamespace
+		if clusterScoped[gvk.GroupKind()] {
+			scope = meta.RESTScopeRoot
+		}
+
+		mapper.Add(gvk, scope)
+	}
+
+	addKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+
+	for _, gvk := range kinds {
+		addKind(gvk)
+	}
+
+	namespaces := make(map[string]bool)
+	initObjs := make([]client.Object, 0, len(objs))
+
+	for _, obj := range objs {
+		gvk, err := gvkForObject(scheme, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		addKind(gvk)
+
+		if clusterScoped[gvk.GroupKind()] {
+			if gvk.Kind == "Namespace" {
+				namespaces[obj.GetName()] = true
+			}
+
+			obj.SetNamespace("")
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace(defaultNamespace)
+		}
+
+		initObjs = append(initObjs, obj)
+	}
+
+	for _, obj := range initObjs {
+		ns := obj.GetNamespace()
+		if ns == "" || namespaces[ns] {
+			continue
+		}
+
+		namespaces[ns] = true
+		initObjs = append(initObjs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(initObjs...).Build(), nil
+}
+
+// gvkForObject returns the kind of the object, from its TypeMeta if it is set,
+// or from the scheme otherwise.
+func gvkForObject(scheme *runtime.Scheme, obj client.Object) (schema.GroupVersionKind, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if !gvk.Empty() {
+		return gvk, nil
+	}
+
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		return schema.GroupVersionKind{}, err
+	}
+
+	return gvks[0], nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReadManifests(t *testing.T) {
+	objs, err := readManifests("testdata/manifests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, len(objs))
+	for i, obj := range objs {
+		got[i] = obj.GetKind() + " " + obj.GetName()
+	}
+
+	// Files are read in lexical order, and the List in deployments.yaml is expanded
+	want := []string{
+		"ConfigMap settings",
+		"ConfigMap settings",
+		"Deployment web",
+		"Deployment worker",
+		"CustomResourceDefinition widgets.example.com",
+		"Widget blue",
+		"Widget red",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected: %v, got: %v", want, got)
+	}
+}
+
+func TestNewFakeCluster(t *testing.T) {
+	objs, err := readManifests("testdata/manifests")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmNoNamespace, err := readManifests("testdata/manifests/configmaps.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmNoNamespace[0].SetName("no-namespace")
+	cmNoNamespace[0].SetNamespace("")
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	initObjs := make([]client.Object, 0, len(objs)+1)
+	for _, obj := range append(objs, cmNoNamespace[0]) {
+		initObjs = append(initObjs, obj)
+	}
+
+	widget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"}
+	gadget := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Gadget"}
+
+	c, err := newFakeCluster(scheme, initObjs, gadget)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nsList := &corev1.NamespaceList{}
+	if err := c.List(context.TODO(), nsList); err != nil {
+		t.Fatal(err)
+	}
+
+	namespaces := make([]string, len(nsList.Items))
+	for i, ns := range nsList.Items {
+		namespaces[i] = ns.Name
+	}
+
+	sort.Strings(namespaces)
+
+	wantNamespaces := []string{"default", "kube-system", "prod"}
+	if !reflect.DeepEqual(namespaces, wantNamespaces) {
+		t.Errorf("expected namespaces: %v, got: %v", wantNamespaces, namespaces)
+	}
+
+	type test struct {
+		gvk       schema.GroupVersionKind
+		wantScope meta.RESTScopeName
+	}
+
+	tests := []test{
+		{gvk: corev1.SchemeGroupVersion.WithKind("ConfigMap"), wantScope: meta.RESTScopeNameNamespace},
+		{gvk: corev1.SchemeGroupVersion.WithKind("Namespace"), wantScope: meta.RESTScopeNameRoot},
+		{gvk: widget, wantScope: meta.RESTScopeNameRoot},
+		{gvk: gadget, wantScope: meta.RESTScopeNameNamespace},
+	}
+
+	for _, tc := range tests {
+		mapping, err := c.RESTMapper().RESTMapping(tc.gvk.GroupKind(), tc.gvk.Version)
+		if err != nil {
+			t.Errorf("test '%v' got error: %v", tc.gvk.Kind, err)
+
+			continue
+		}
+
+		if mapping.Scope.Name() != tc.wantScope {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.gvk.Kind, tc.wantScope, mapping.Scope.Name())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policyctl implements the policyctl command, which works with policies
+// in the policy framework, mostly without a cluster, for example in CI
+// pipelines.
+package policyctl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exit codes returned by Run.
+const (
+	// ExitOK means the command succeeded, and found no problems.
+	ExitOK = 0
+	// ExitError means the command could not do what was asked, for example
+	// because a file could not be read.
+	ExitError = 1
+	// ExitViolations means the command succeeded, but found problems, for
+	// example policies which are not compliant.
+	ExitViolations = 2
+)
+
+// command is a subcommand of policyctl.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands returns the subcommands of policyctl, in the order they are listed
+// in the usage message.
+func commands() []command {
+	return []command{
+		{name: "evaluate", summary: "Evaluate policies against YAML manifests", run: runEvaluate},
+		{name: "lint", summary: "Check policy manifests for mistakes", run: runLint},
+		{name: "report", summary: "Export a compliance report of the policies on a cluster", run: runReport},
+	}
+}
+
+// Run runs policyctl with the given arguments, not including the program name,
+// and returns the exit code.
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+
+		return ExitError
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+
+		return ExitOK
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+	usage(stderr)
+
+	return ExitError
+}
+
+// usage writes the list of subcommands to the writer.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: policyctl <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-10v %v\n", cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'policyctl <command> -h' for the flags of a command.")
+}
+
+// pathList is a flag.Value for flags which can be given more than once.
+type pathList []string
+
+func (p *pathList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *pathList) Set(val string) error {
+	*p = append(*p, val)
+
+	return nil
+}
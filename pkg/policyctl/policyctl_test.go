@@ -0,0 +1,73 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	type test struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}
+
+	tests := []test{
+		{
+			name:       "no arguments",
+			args:       []string{},
+			wantCode:   ExitError,
+			wantStderr: "Usage: policyctl <command> [flags]",
+		}, {
+			name:       "help",
+			args:       []string{"help"},
+			wantCode:   ExitOK,
+			wantStdout: "evaluate",
+		}, {
+			name:       "unknown command",
+			args:       []string{"frobnicate"},
+			wantCode:   ExitError,
+			wantStderr: `unknown command "frobnicate"`,
+		}, {
+			name:       "command help",
+			args:       []string{"evaluate", "-h"},
+			wantCode:   ExitOK,
+			wantStderr: "Usage: policyctl evaluate",
+		},
+	}
+
+	for _, tc := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := Run(tc.args, stdout, stderr); code != tc.wantCode {
+			t.Errorf("test '%v' expected exit code %v, got: %v", tc.name, tc.wantCode, code)
+		}
+
+		if !strings.Contains(stdout.String(), tc.wantStdout) {
+			t.Errorf("test '%v' expected stdout to contain: %v, got: %v", tc.name, tc.wantStdout, stdout)
+		}
+
+		if !strings.Contains(stderr.String(), tc.wantStderr) {
+			t.Errorf("test '%v' expected stderr to contain: %v, got: %v", tc.name, tc.wantStderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/pkg/report"
+)
+
+// runReport implements the report command.
+func runReport(args []string, stdout, stderr io.Writer) int {
+	var manifestPaths, kindArgs pathList
+
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Var(&manifestPaths, "manifests",
+		"A file or directory of policies to report on, instead of a cluster. Can be given more than once.")
+	flags.Var(&kindArgs, "kind",
+		"A kind of policy to report on, like Kind.version.group. Can be given more than once.")
+	kubeconfig := flags.String("kubeconfig", "",
+		"The kubeconfig file of the cluster. By default, the KUBECONFIG environment variable or ~/.kube/config is used.")
+	namespace := flags.String("n", "", "Only report on policies in this namespace.")
+	output := flags.String("o", report.FormatJSON, "The output format: "+strings.Join(report.Formats, ", ")+".")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: policyctl report [--kubeconfig <path> | --manifests <path>] [--kind <kind>]")
+		fmt.Fprintln(stderr, "                        [-n <namespace>] [-o json|csv|junit|sarif]")
+		fmt.Fprintln(stderr)
+		fmt.Fprintln(stderr, "Reports the compliance of the policies on a cluster, or in the manifests, grouped")
+		fmt.Fprintln(stderr, "by severity and namespace. By default, every kind in the policy group is")
+		fmt.Fprintln(stderr, "included, except for the kinds which are not policies and the Policy kind")
+		fmt.Fprintln(stderr, "which wraps other policies. The exit code is 2 when a policy is not compliant.")
+		fmt.Fprintln(stderr)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+
+		return ExitError
+	}
+
+	if !containsString(report.Formats, *output) {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+
+		return ExitError
+	}
+
+	kinds := make([]schema.GroupVersionKind, 0, len(kindArgs))
+
+	for _, arg := range kindArgs {
+		gvk, _ := schema.ParseKindArg(arg)
+		if gvk == nil {
+			fmt.Fprintf(stderr, "invalid kind %q, expected Kind.version.group\n", arg)
+
+			return ExitError
+		}
+
+		kinds = append(kinds, *gvk)
+	}
+
+	var (
+		c   client.Client
+		err error
+	)
+
+	if len(manifestPaths) != 0 {
+		c, kinds, err = manifestCluster(manifestPaths, kinds)
+	} else {
+		c, kinds, err = liveCluster(*kubeconfig, kinds)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	opts := make([]client.ListOption, 0, 1)
+	if *namespace != "" {
+		opts = append(opts, client.InNamespace(*namespace))
+	}
+
+	rep, err := report.CollectKinds(context.Background(), c, kinds, opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	if err := report.Write(stdout, rep, *output); err != nil {
+		fmt.Fprintln(stderr, err)
+
+		return ExitError
+	}
+
+	for _, group := range rep.Groups {
+		for _, p := range group.Policies {
+			if p.Failed() {
+				return ExitViolations
+			}
+		}
+	}
+
+	return ExitOK
+}
+
+// manifestCluster returns a client for an in-memory cluster which holds the
+// objects in the manifests. If no kinds are given, the kinds of the policies in
+// the manifests are returned.
+func manifestCluster(
+	paths []string, kinds []schema.GroupVersionKind,
+) (client.Client, []schema.GroupVersionKind, error) {
+	manifests, err := readManifests(paths...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	objs := make([]client.Object, 0, len(manifests))
+	found := make(map[schema.GroupVersionKind]bool)
+
+	for _, obj := range manifests {
+		objs = append(objs, obj)
+
+		if isReportedKind(obj.GroupVersionKind()) {
+			found[obj.GroupVersionKind()] = true
+		}
+	}
+
+	if len(kinds) == 0 {
+		for gvk := range found {
+			kinds = append(kinds, gvk)
+		}
+
+		sortKinds(kinds)
+	}
+
+	c, err := newFakeCluster(scheme, objs, kinds...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, kinds, nil
+}
+
+// liveCluster returns a client for the cluster in the kubeconfig file, or in
+// the default kubeconfig if the path is empty. If no kinds are given, the
+// policy kinds served by the cluster are found with discovery.
+func liveCluster(
+	kubeconfig string, kinds []schema.GroupVersionKind,
+) (client.Client, []schema.GroupVersionKind, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).
+		ClientConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load the kubeconfig: %w", err)
+	}
+
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(kinds) != 0 {
+		return c, kinds, nil
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources, err := discoveryClient.ServerPreferredResources()
+	if err != nil && len(resources) == 0 {
+		return nil, nil, fmt.Errorf("unable to discover the policy kinds: %w", err)
+	}
+
+	for _, list := range resources {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range list.APIResources {
+			gvk := gv.WithKind(resource.Kind)
+
+			// Subresources, like status, have the kind of their parent
+			if !strings.Contains(resource.Name, "/") && isReportedKind(gvk) {
+				kinds = append(kinds, gvk)
+			}
+		}
+	}
+
+	sortKinds(kinds)
+
+	return c, kinds, nil
+}
+
+// isReportedKind returns true if policies of the kind are included in reports
+// by default: kinds in the policy group, except for the ones which are not
+// policies, and parent policies, whose status summarizes the policies they
+// wrap.
+func isReportedKind(gvk schema.GroupVersionKind) bool {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+
+	return isPolicy(obj) && !isParentPolicyKind(gvk.GroupKind())
+}
+
+// sortKinds sorts the kinds, so that reports list them in a consistent order.
+func sortKinds(kinds []schema.GroupVersionKind) {
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+}
+
+// containsString returns true if the value is in the list.
+func containsString(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/JustinKuli/policy-framework/pkg/report"
+)
+
+func TestReport(t *testing.T) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	code := Run([]string{"report", "--manifests", "testdata/report"}, stdout, stderr)
+	if code != ExitViolations {
+		t.Fatalf("expected exit code %v, got: %v, stderr: %v", ExitViolations, code, stderr)
+	}
+
+	rep := report.Report{}
+	if err := json.Unmarshal(stdout.Bytes(), &rep); err != nil {
+		t.Fatal(err)
+	}
+
+	wantSummary := report.Summary{Total: 3, Compliant: 1, NonCompliant: 1, Unknown: 1}
+	if rep.Summary != wantSummary {
+		t.Errorf("expected summary: %+v, got: %+v", wantSummary, rep.Summary)
+	}
+
+	got := make([]string, 0)
+
+	for _, group := range rep.Groups {
+		for _, p := range group.Policies {
+			got = append(got, strings.Join([]string{group.Name(), p.Kind, p.Metadata.Name}, " "))
+		}
+	}
+
+	want := []string{
+		"high/default ConfigurationPolicy require-configmap",
+		"low/apps CertificatePolicy certificates",
+		"unspecified/apps ConfigurationPolicy replicas",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected policies: %v, got: %v", want, got)
+	}
+}
+
+func TestReportFlags(t *testing.T) {
+	type test struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}
+
+	tests := []test{
+		{
+			name:       "junit",
+			args:       []string{"-o", "junit"},
+			wantCode:   ExitViolations,
+			wantStdout: `<testsuite name="high/default" tests="1" failures="1" skipped="0">`,
+		}, {
+			name:       "csv in a namespace",
+			args:       []string{"-o", "csv", "-n", "apps"},
+			wantCode:   ExitViolations,
+			wantStdout: ",apps,policy.open-cluster-management.io/v1,ConfigurationPolicy,replicas,",
+		}, {
+			name:       "sarif for one kind",
+			args:       []string{"-o", "sarif", "--kind", "CertificatePolicy.v1.policy.open-cluster-management.io"},
+			wantCode:   ExitOK,
+			wantStdout: `"id": "policy.open-cluster-management.io/v1/CertificatePolicy/apps/certificates"`,
+		}, {
+			name:       "unknown format",
+			args:       []string{"-o", "yaml"},
+			wantCode:   ExitError,
+			wantStderr: `unknown output format "yaml"`,
+		}, {
+			name:       "invalid kind",
+			args:       []string{"--kind", "CertificatePolicy"},
+			wantCode:   ExitError,
+			wantStderr: `invalid kind "CertificatePolicy"`,
+		},
+	}
+
+	for _, tc := range tests {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		args := append([]string{"report", "--manifests", "testdata/report"}, tc.args...)
+
+		code := Run(args, stdout, stderr)
+		if code != tc.wantCode {
+			t.Errorf("test '%v' expected exit code: %v, got: %v, stderr: %v", tc.name, tc.wantCode, code, stderr)
+		}
+
+		if !strings.Contains(stdout.String(), tc.wantStdout) {
+			t.Errorf("test '%v' expected stdout to contain: %v, got: %v", tc.name, tc.wantStdout, stdout)
+		}
+
+		if !strings.Contains(stderr.String(), tc.wantStderr) {
+			t.Errorf("test '%v' expected stderr to contain: %v, got: %v", tc.name, tc.wantStderr, stderr)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policyctl
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	crdvalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// FrameworkCRDs holds the CustomResourceDefinitions of the policy framework.
+// The lint command checks the spec of every policy against the schema of the
+// PolicyType CRD in it, since every policy type includes the PolicyTypeSpec
+// fields. The policyctl command sets it to the files in config/crd/bases.
+var FrameworkCRDs fs.FS
+
+// schemaValidators checks objects against the schemas in CRDs.
+type schemaValidators struct {
+	// kinds has a validator for each version of each kind with a CRD.
+	kinds map[schema.GroupVersionKind]*validate.SchemaValidator
+	// policyType is the validator for the v1alpha1 PolicyType, which is used
+	// for policies of kinds without a CRD, since policy types embed the
+	// v1alpha1 PolicyTypeSpec.
+	policyType *validate.SchemaValidator
+}
+
+// newSchemaValidators returns validators for the schemas in the CRDs, skipping
+// any other objects.
+func newSchemaValidators(crds []*unstructured.Unstructured) (*schemaValidators, error) {
+	validators := &schemaValidators{kinds: make(map[schema.GroupVersionKind]*validate.SchemaValidator)}
+	policyTypeGVK := v1alpha1.GroupVersion.WithKind("PolicyType")
+
+	for _, obj := range crds {
+		if obj.GroupVersionKind() != apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition") {
+			continue
+		}
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, crd); err != nil {
+			return nil, fmt.Errorf("invalid CustomResourceDefinition %v: %w", obj.GetName(), err)
+		}
+
+		for _, version := range crd.Spec.Versions {
+			if version.Schema == nil {
+				continue
+			}
+
+			internal := &apiextensions.CustomResourceValidation{}
+
+			err := apiextensionsv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(
+				version.Schema, internal, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema in CustomResourceDefinition %v: %w", crd.Name, err)
+			}
+
+			validator, _, err := crdvalidation.NewSchemaValidator(internal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema in CustomResourceDefinition %v: %w", crd.Name, err)
+			}
+
+			gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: version.Name, Kind: crd.Spec.Names.Kind}
+			validators.kinds[gvk] = validator
+
+			if gvk == policyTypeGVK {
+				validators.policyType = validator
+			}
+		}
+	}
+
+	return validators, nil
+}
+
+// readCRDs returns the objects in the YAML and JSON files in the filesystem.
+func readCRDs(fsys fs.FS) ([]*unstructured.Unstructured, error) {
+	objs := make([]*unstructured.Unstructured, 0)
+
+	err := fs.WalkDir(fsys, ".", func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || !isManifestFile(file) {
+			return nil
+		}
+
+		f, err := fsys.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fileObjs, err := decodeManifests(f)
+		if err != nil {
+			return fmt.Errorf("unable to read %v: %w", file, err)
+		}
+
+		objs = append(objs, fileObjs...)
+
+		return nil
+	})
+
+	return objs, err
+}
+
+// validate checks the policy against the schema of its kind when there is a
+// CRD for it, or otherwise checks its spec against the PolicyType schema. The
+// errors are sorted by field, type, and detail, since the schema validation
+// reports them in a random order.
+func (v *schemaValidators) validate(policy *unstructured.Unstructured) field.ErrorList {
+	errs := field.ErrorList{}
+
+	if validator, found := v.kinds[policy.GroupVersionKind()]; found {
+		errs = crdvalidation.ValidateCustomResource(nil, policy.Object, validator)
+	} else if v.policyType != nil {
+		// Only the spec is checked, since the rest of the PolicyType schema is
+		// specific to that kind
+		errs = crdvalidation.ValidateCustomResource(nil, map[string]interface{}{
+			"spec": policy.Object["spec"],
+		}, v.policyType)
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		if errs[i].Field != errs[j].Field {
+			return errs[i].Field < errs[j].Field
+		}
+
+		if errs[i].Type != errs[j].Type {
+			return errs[i].Type < errs[j].Type
+		}
+
+		return errs[i].Detail < errs[j].Detail
+	})
+
+	return errs
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// WatchNamespaces adds a watch on namespaces to the given controller builder,
+// so that when a namespace is created, deleted, or has its labels changed, every
+// policy whose NamespaceSelector selects that namespace will be reconciled. The
+// watch also causes the manager's cache to keep an informer on namespaces, so
+// calls to NamespaceSelector.GetNamespaces with the manager's client will read
+// from that cache instead of the API server. The client.Reader needs access to
+// list the policies, and to view namespaces, like the access given by this
+// kubebuilder tag (in addition to the policy type's own tags):
+// `//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch`
+func WatchNamespaces(
+	blder *builder.Builder, r client.Reader, newList func() client.ObjectList,
+) *builder.Builder {
+	return blder.Watches(
+		&source.Kind{Type: &corev1.Namespace{}},
+		handler.EnqueueRequestsFromMapFunc(namespacePolicies(r, newList)),
+		builder.WithPredicates(predicate.LabelChangedPredicate{}),
+	)
+}
+
+// namespacePolicies returns a MapFunc which lists all of the policies, and
+// returns requests for the ones which select the given namespace. On updates,
+// the handler calls the MapFunc for both the old and new namespace, so policies
+// which no longer select a relabeled namespace are also reconciled.
+func namespacePolicies(r client.Reader, newList func() client.ObjectList) handler.MapFunc {
+	return func(namespace client.Object) []reconcile.Request {
+		ctx := context.TODO()
+		log := ctrllog.FromContext(ctx).WithValues("namespace", namespace.GetName())
+
+		list := newList()
+		if err := r.List(ctx, list); err != nil {
+			log.Error(err, "Failed to list policies for namespace event")
+			return nil
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			log.Error(err, "Failed to extract policies from list")
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0)
+		for _, item := range items {
+			policy, ok := item.(v1alpha1.PolicyTyper)
+			if !ok {
+				continue
+			}
+
+			selected, err := policy.PolicySpec().NamespaceSelector.Selects(namespace)
+			if err != nil {
+				log.Error(err, "Failed to check if policy selects namespace",
+					"policy", policy.GetNamespace()+"/"+policy.GetName())
+				continue
+			}
+
+			if selected {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: policy.GetNamespace(),
+					Name:      policy.GetName(),
+				}})
+			}
+		}
+
+		return requests
+	}
+}
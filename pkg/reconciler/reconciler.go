@@ -0,0 +1,230 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package reconciler contains a generic reconciler which handles the parts of
+// reconciling a policy that are the same for every policy type in the policy
+// framework, so that controllers only need to implement their evaluation logic.
+package reconciler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/pkg/events"
+	"github.com/JustinKuli/policy-framework/pkg/metrics"
+)
+
+// EvaluateFunc determines the compliance of the given policy. It returns the
+// ComplianceState, the objects that were examined, and the reason and message
+// to use in the Compliant condition and in the compliance event. If an error is
+// returned, the policy will be marked with the PolicyError reason, and the
+// request will be requeued.
+type EvaluateFunc func(ctx context.Context, policy v1alpha1.PolicyTyper) (
+	state v1alpha1.ComplianceState, related []v1alpha1.RelatedObject, reason, msg string, err error)
+
+// PolicyReconciler reconciles any policy type in the policy framework. It gets
+// the policy, evaluates it with the Evaluate function, updates the status and
+// the Compliant condition, records a compliance event on the parent policy when
+// the compliance has changed, and updates the metrics in the metrics package.
+type PolicyReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	// NewPolicy should return a new, empty instance of the concrete policy type
+	// being reconciled, for example `&MockPolicy{}`.
+	NewPolicy func() v1alpha1.PolicyTyper
+
+	// NewPolicyList is optional. If set, it should return a new, empty list of
+	// the concrete policy type, for example `&MockPolicyList{}`, and policies
+	// will be reconciled when a namespace they select is created, deleted, or
+	// relabeled. See WatchNamespaces for details.
+	NewPolicyList func() client.ObjectList
+
+	// EventHeartbeat is optional. Compliance events are only emitted when the
+	// compliance or message of the policy changes, but if this is set, an event
+	// is also emitted when the last one for the policy is at least this old.
+	EventHeartbeat time.Duration
+
+	// Evaluate is called on every reconcile to determine the compliance of the
+	// policy. It may also set fields in the policy's status which are specific
+	// to the policy type; those will be saved with the rest of the status.
+	Evaluate EvaluateFunc
+
+	eventsOnce sync.Once
+	events     *events.ComplianceEventRecorder
+}
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state. The
+// client.Client needs access to get the policy and update its status, and the
+// record.EventRecorder needs access to create events, like the access given by
+// these kubebuilder tags (with the resource adjusted for the policy type):
+// `//+kubebuilder:rbac:groups=policy.open-cluster-management.io,resources=mockpolicies,verbs=get;list;watch`
+// `//+kubebuilder:rbac:groups=policy.open-cluster-management.io,resources=mockpolicies/status,verbs=get;update;patch`
+// `//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch`
+func (r *PolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	policy := r.NewPolicy()
+
+	gvk, err := apiutil.GVKForObject(policy, r.Scheme())
+	if err != nil {
+		log.Error(err, "Failed to determine the kind of the policy")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, probably deleted
+			metrics.DeleteCompliance(gvk.Kind, req.Namespace, req.Name)
+			r.eventRecorder().Forget(req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get policy")
+		return ctrl.Result{}, err
+	}
+
+	state, related, reason, msg, evalErr := r.Evaluate(ctx, policy)
+	metrics.RecordEvaluation(gvk.Kind, evalErr)
+	if evalErr != nil {
+		log.Error(evalErr, "Failed to evaluate policy")
+
+		state = v1alpha1.UnknownCompliancy
+		reason = v1alpha1.ReasonPolicyError
+		if msg == "" {
+			msg = evalErr.Error()
+		}
+	}
+
+	status := policy.PolicyStatus()
+	status.ComplianceState = state
+	status.RelatedObjects = related
+	status.LastEvaluated = metav1.Now()
+
+	if reason == "" {
+		reason = defaultReason(state)
+	}
+
+	v1alpha1.UpdatePolicyCondition(policy, reason, msg)
+
+	if evalErr != nil {
+		v1alpha1.SetReadyCondition(status, false, v1alpha1.ReasonPolicyError, msg)
+	} else {
+		v1alpha1.SetReadyCondition(status, true, v1alpha1.ReasonEvaluated, "The policy was evaluated")
+	}
+
+	if err := r.Status().Update(ctx, policy); err != nil {
+		log.Error(err, "Failed to update status")
+		return ctrl.Result{}, err
+	}
+
+	metrics.RecordCompliance(gvk.Kind, policy)
+
+	if _, err := r.eventRecorder().Record(policy, msg); err != nil {
+		// Policies without a parent are still evaluated, they just can't report it
+		log.V(1).Info("Compliance event not recorded", "reason", err.Error())
+	}
+
+	if evalErr != nil {
+		// Returning the error requeues the request with the usual backoff
+		return ctrl.Result{}, evalErr
+	}
+
+	requeueAfter, err := v1alpha1.RequeueAfter(policy, time.Now())
+	if err != nil {
+		log.Error(err, "Failed to determine when to evaluate the policy again")
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Policies are
+// evaluated when they are created, when their spec, labels, or owner references
+// change, and periodically according to their EvaluationInterval.
+func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Changes to the status should not trigger an evaluation, since the status
+	// is updated on every evaluation. The owner references determine the parent
+	// which receives the compliance events, so a new parent is sent an event.
+	blder := ctrl.NewControllerManagedBy(mgr).
+		For(r.NewPolicy(), builder.WithPredicates(predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+			OwnerReferencesChangedPredicate{},
+		)))
+
+	if r.NewPolicyList != nil {
+		blder = WatchNamespaces(blder, r.Client, r.NewPolicyList)
+	}
+
+	return blder.Complete(r)
+}
+
+// OwnerReferencesChangedPredicate implements a default update predicate
+// function on owner references change, like predicate.LabelChangedPredicate
+// does for labels.
+type OwnerReferencesChangedPredicate struct {
+	predicate.Funcs
+}
+
+// Update implements the default UpdateEvent filter for validating owner
+// references change.
+func (OwnerReferencesChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+
+	return !equality.Semantic.DeepEqual(e.ObjectOld.GetOwnerReferences(), e.ObjectNew.GetOwnerReferences())
+}
+
+// eventRecorder returns the ComplianceEventRecorder for this reconciler,
+// creating it on first use.
+func (r *PolicyReconciler) eventRecorder() *events.ComplianceEventRecorder {
+	r.eventsOnce.Do(func() {
+		r.events = &events.ComplianceEventRecorder{
+			Recorder:          r.Recorder,
+			HeartbeatInterval: r.EventHeartbeat,
+		}
+	})
+
+	return r.events
+}
+
+// defaultReason returns the standard reason for the given ComplianceState, to
+// be used when the Evaluate function does not provide one.
+func defaultReason(state v1alpha1.ComplianceState) string {
+	switch state {
+	case v1alpha1.Compliant:
+		return v1alpha1.ReasonPolicyCompliant
+	case v1alpha1.NonCompliant:
+		return v1alpha1.ReasonViolationsFound
+	default:
+		return v1alpha1.ReasonComplianceUnknown
+	}
+}
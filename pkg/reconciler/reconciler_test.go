@@ -0,0 +1,395 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	mockv1alpha1 "github.com/JustinKuli/policy-framework/test/mockpolicy/api/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	type test struct {
+		name       string
+		evaluate   EvaluateFunc
+		wantErr    bool
+		wantState  v1alpha1.ComplianceState
+		wantReason string
+		wantReady  metav1.ConditionStatus
+		wantEvent  string
+	}
+
+	tests := []test{
+		{
+			name: "compliant with default reason",
+			evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+				v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+			) {
+				return v1alpha1.Compliant, nil, "", "all good", nil
+			},
+			wantState:  v1alpha1.Compliant,
+			wantReason: v1alpha1.ReasonPolicyCompliant,
+			wantReady:  metav1.ConditionTrue,
+			wantEvent:  "Normal policy: default/test Compliant; all good",
+		}, {
+			name: "noncompliant with custom reason",
+			evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+				v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+			) {
+				return v1alpha1.NonCompliant, nil, v1alpha1.ReasonNoCompliantObjects, "missing", nil
+			},
+			wantState:  v1alpha1.NonCompliant,
+			wantReason: v1alpha1.ReasonNoCompliantObjects,
+			wantReady:  metav1.ConditionTrue,
+			wantEvent:  "Warning policy: default/test NonCompliant; missing",
+		}, {
+			name: "unknown with default reason",
+			evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+				v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+			) {
+				return v1alpha1.UnknownCompliancy, nil, "", "not sure", nil
+			},
+			wantState:  v1alpha1.UnknownCompliancy,
+			wantReason: v1alpha1.ReasonComplianceUnknown,
+			wantReady:  metav1.ConditionTrue,
+			wantEvent:  "Warning policy: default/test NonCompliant; not sure",
+		}, {
+			name: "evaluation error",
+			evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+				v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+			) {
+				return v1alpha1.Compliant, nil, "", "", errors.New("boom")
+			},
+			wantErr:    true,
+			wantState:  v1alpha1.UnknownCompliancy,
+			wantReason: v1alpha1.ReasonPolicyError,
+			wantReady:  metav1.ConditionFalse,
+			wantEvent:  "Warning policy: default/test NonCompliant; boom",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := mockv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+
+			policy := &mockv1alpha1.MockPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "test",
+					Namespace:  "default",
+					Generation: 2,
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "policy.open-cluster-management.io/v1",
+						Kind:       "Policy",
+						Name:       "parent",
+					}},
+				},
+			}
+
+			recorder := record.NewFakeRecorder(1)
+			r := &PolicyReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build(),
+				Recorder: recorder,
+				NewPolicy: func() v1alpha1.PolicyTyper {
+					return &mockv1alpha1.MockPolicy{}
+				},
+				Evaluate: tc.evaluate,
+			}
+
+			key := types.NamespacedName{Namespace: "default", Name: "test"}
+			_, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Reconcile() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			got := &mockv1alpha1.MockPolicy{}
+			if err := r.Get(context.TODO(), key, got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Status.ComplianceState != tc.wantState {
+				t.Errorf("ComplianceState = %v, want %v", got.Status.ComplianceState, tc.wantState)
+			}
+
+			if !v1alpha1.IsStatusCurrent(got) {
+				t.Errorf("status is not current: generation %v, status %+v", got.Generation, got.Status)
+			}
+
+			cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ComplianceConditionType)
+			if cond == nil {
+				t.Fatal("Compliant condition was not set")
+			}
+			if cond.Reason != tc.wantReason {
+				t.Errorf("condition reason = %v, want %v", cond.Reason, tc.wantReason)
+			}
+
+			ready := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.ReadyConditionType)
+			if ready == nil || ready.Status != tc.wantReady {
+				t.Errorf("Ready condition = %+v, want status %v", ready, tc.wantReady)
+			}
+
+			select {
+			case event := <-recorder.Events:
+				if event != tc.wantEvent {
+					t.Errorf("event = %q, want %q", event, tc.wantEvent)
+				}
+			default:
+				t.Error("no compliance event was recorded")
+			}
+		})
+	}
+}
+
+func TestReconcileNotFound(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := mockv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &PolicyReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Recorder: record.NewFakeRecorder(1),
+		NewPolicy: func() v1alpha1.PolicyTyper {
+			return &mockv1alpha1.MockPolicy{}
+		},
+		Evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+			v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+		) {
+			t.Error("Evaluate should not be called when the policy is not found")
+			return "", nil, "", "", nil
+		},
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "missing"}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Errorf("Reconcile() error = %v, want nil", err)
+	}
+}
+
+func TestNamespacePolicies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := mockv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newPolicy := func(name string, sel v1alpha1.NamespaceSelector) *mockv1alpha1.MockPolicy {
+		return &mockv1alpha1.MockPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: mockv1alpha1.MockPolicySpec{
+				PolicyTypeSpec: v1alpha1.PolicyTypeSpec{NamespaceSelector: sel},
+			},
+		}
+	}
+
+	r := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newPolicy("all", v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}),
+		newPolicy("kube", v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"kube-*"}}),
+		newPolicy("prod", v1alpha1.NamespaceSelector{
+			Include:     []v1alpha1.NonEmptyString{"*"},
+			MatchLabels: map[string]string{"env": "prod"},
+		}),
+	).Build()
+
+	mapFunc := namespacePolicies(r, func() client.ObjectList { return &mockv1alpha1.MockPolicyList{} })
+
+	type test struct {
+		name      string
+		namespace *corev1.Namespace
+		want      []string
+	}
+
+	tests := []test{
+		{
+			name:      "unlabeled namespace",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo"}},
+			want:      []string{"all"},
+		}, {
+			name:      "kube namespace",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-foo"}},
+			want:      []string{"all", "kube"},
+		}, {
+			name: "prod namespace",
+			namespace: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:   "payments",
+				Labels: map[string]string{"env": "prod"},
+			}},
+			want: []string{"all", "prod"},
+		},
+	}
+
+	for _, tc := range tests {
+		got := make([]string, 0)
+		for _, req := range mapFunc(tc.namespace) {
+			got = append(got, req.Name)
+		}
+
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestReconcileNewParent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := mockv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := func(name string, uid types.UID) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{
+			APIVersion: "policy.open-cluster-management.io/v1",
+			Kind:       "Policy",
+			Name:       name,
+			UID:        uid,
+		}}
+	}
+
+	policy := &mockv1alpha1.MockPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "test",
+			Namespace:       "default",
+			OwnerReferences: parent("first", "uid-1"),
+		},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := &PolicyReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy).Build(),
+		Recorder: recorder,
+		NewPolicy: func() v1alpha1.PolicyTyper {
+			return &mockv1alpha1.MockPolicy{}
+		},
+		Evaluate: func(context.Context, v1alpha1.PolicyTyper) (
+			v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+		) {
+			return v1alpha1.Compliant, nil, "", "all good", nil
+		},
+	}
+
+	key := types.NamespacedName{Namespace: "default", Name: "test"}
+	reconcile := func() int {
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatal(err)
+		}
+
+		return len(recorder.Events)
+	}
+
+	if got := reconcile(); got != 1 {
+		t.Fatalf("expected 1 event for the first parent, got: %v", got)
+	}
+
+	<-recorder.Events
+
+	if got := reconcile(); got != 0 {
+		t.Fatalf("expected no event when nothing changed, got: %v", got)
+	}
+
+	current := &mockv1alpha1.MockPolicy{}
+	if err := r.Get(context.TODO(), key, current); err != nil {
+		t.Fatal(err)
+	}
+
+	current.OwnerReferences = parent("second", "uid-2")
+	if err := r.Update(context.TODO(), current); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := reconcile(); got != 1 {
+		t.Errorf("expected 1 event for the new parent, got: %v", got)
+	}
+}
+
+func TestOwnerReferencesChangedPredicate(t *testing.T) {
+	newPolicy := func(labels map[string]string, owners ...string) *mockv1alpha1.MockPolicy {
+		policy := &mockv1alpha1.MockPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Labels: labels},
+		}
+
+		for _, owner := range owners {
+			policy.OwnerReferences = append(policy.OwnerReferences, metav1.OwnerReference{
+				APIVersion: "policy.open-cluster-management.io/v1",
+				Kind:       "Policy",
+				Name:       owner,
+			})
+		}
+
+		return policy
+	}
+
+	type test struct {
+		name   string
+		oldObj client.Object
+		newObj client.Object
+		want   bool
+	}
+
+	tests := []test{
+		{
+			name:   "unchanged",
+			oldObj: newPolicy(nil, "parent"),
+			newObj: newPolicy(nil, "parent"),
+			want:   false,
+		}, {
+			name:   "only labels changed",
+			oldObj: newPolicy(nil, "parent"),
+			newObj: newPolicy(map[string]string{"a": "b"}, "parent"),
+			want:   false,
+		}, {
+			name:   "owner added",
+			oldObj: newPolicy(nil),
+			newObj: newPolicy(nil, "parent"),
+			want:   true,
+		}, {
+			name:   "owner replaced",
+			oldObj: newPolicy(nil, "parent"),
+			newObj: newPolicy(nil, "other"),
+			want:   true,
+		}, {
+			name:   "missing old object",
+			newObj: newPolicy(nil, "parent"),
+			want:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		got := OwnerReferencesChangedPredicate{}.Update(event.UpdateEvent{ObjectOld: tc.oldObj, ObjectNew: tc.newObj})
+		if got != tc.want {
+			t.Errorf("test '%v' expected: %v, got: %v", tc.name, tc.want, got)
+		}
+	}
+}
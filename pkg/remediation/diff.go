@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// ignoredMetadata are the metadata fields which are set by the API server, and
+// would only add noise to a diff.
+var ignoredMetadata = []string{
+	"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink",
+}
+
+// unifiedDiff returns a unified diff between the YAML of the objects, either of
+// which may be nil. It returns an empty string if there are no differences.
+func unifiedDiff(name string, before, after *unstructured.Unstructured) (string, error) {
+	beforeLines, err := diffableLines(before)
+	if err != nil {
+		return "", err
+	}
+
+	afterLines, err := diffableLines(after)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        beforeLines,
+		B:        afterLines,
+		FromFile: "live/" + name,
+		ToFile:   "dryrun/" + name,
+		Context:  3,
+	})
+}
+
+// diffableLines returns the lines of the object's YAML, without the metadata
+// fields which are set by the API server.
+func diffableLines(obj *unstructured.Unstructured) ([]string, error) {
+	if obj == nil {
+		return nil, nil
+	}
+
+	obj = obj.DeepCopy()
+	for _, field := range ignoredMetadata {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+
+	out, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	// SplitLines adds a newline to the last line, which yaml already ends with
+	return difflib.SplitLines(strings.TrimSuffix(string(out), "\n")), nil
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// unprunedFields are the top-level fields which are never removed from an
+// object for MustOnlyHave, since they are not part of what a policy describes.
+var unprunedFields = []string{"apiVersion", "kind", "metadata", "status"}
+
+// prunedMetadata are the metadata fields which have their extra keys removed for
+// MustOnlyHave, when they are in the desired object.
+var prunedMetadata = []string{"labels", "annotations"}
+
+// ownedFields is a tree of the fields which are owned by field managers, in the
+// format of the FieldsV1 in managedFields. Each key is a field, like "f:data",
+// or a list item, like `k:{"name":"app"}`, `v:"value"`, or "i:0", and it maps
+// to the owned fields inside of it.
+type ownedFields map[string]interface{}
+
+// othersFields returns the fields of the object which are owned by any field
+// manager other than the given one. Fields which are not owned by any manager,
+// like the ones which the API server defaults, are not included. Subresources
+// like the status are skipped, since they are never pruned.
+func othersFields(obj *unstructured.Unstructured, manager string) (ownedFields, error) {
+	owned := ownedFields{}
+
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Manager == manager || entry.Subresource != "" || entry.FieldsV1 == nil {
+			continue
+		}
+
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal(entry.FieldsV1.Raw, &fields); err != nil {
+			return nil, err
+		}
+
+		mergeOwned(owned, fields)
+	}
+
+	return owned, nil
+}
+
+// mergeOwned adds the fields to the tree of owned fields.
+func mergeOwned(owned ownedFields, fields map[string]interface{}) {
+	for key, val := range fields {
+		child, _ := val.(map[string]interface{})
+
+		existing, found := owned[key].(ownedFields)
+		if !found {
+			existing = ownedFields{}
+			owned[key] = existing
+		}
+
+		mergeOwned(existing, child)
+	}
+}
+
+// field returns the owned fields inside of the map field with the given key, or
+// nil if it is not owned.
+func (o ownedFields) field(key string) ownedFields {
+	child, _ := o["f:"+key].(ownedFields)
+
+	return child
+}
+
+// item returns the key of the list item in the owned fields, and the owned
+// fields inside of it, or an empty key if the item is not owned.
+func (o ownedFields) item(index int, item interface{}) (string, ownedFields) {
+	for key, val := range o {
+		if itemMatches(key, index, item) {
+			child, _ := val.(ownedFields)
+
+			return key, child
+		}
+	}
+
+	return "", nil
+}
+
+// itemMatches returns true if the key from the owned fields identifies the
+// list item: by its index, by its whole value, or by the values of its key
+// fields.
+func itemMatches(key string, index int, item interface{}) bool {
+	switch {
+	case strings.HasPrefix(key, "i:"):
+		return key == "i:"+strconv.Itoa(index)
+	case strings.HasPrefix(key, "v:"):
+		return jsonEqual(strings.TrimPrefix(key, "v:"), item)
+	case strings.HasPrefix(key, "k:"):
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		keyFields := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(key, "k:")), &keyFields); err != nil {
+			return false
+		}
+
+		for name, val := range keyFields {
+			if itemVal, found := itemMap[name]; !found || !jsonEqual(string(val), itemVal) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+// jsonEqual returns true if the JSON is equal to the value, which avoids
+// differences between the number types of decoded JSON and unstructured objects.
+func jsonEqual(raw string, val interface{}) bool {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(decoded, runtimeJSON(val))
+}
+
+// runtimeJSON returns the value as it would be decoded from JSON.
+func runtimeJSON(val interface{}) interface{} {
+	raw, err := json.Marshal(val)
+	if err != nil {
+		return val
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return val
+	}
+
+	return decoded
+}
+
+// pruneObject removes the fields from the live object which are not in the
+// desired object and which are owned by other field managers, and returns true
+// if anything was removed. Fields without an owner, like the ones which the API
+// server defaults, are kept, so that a converged object is left unchanged.
+func pruneObject(live, desired map[string]interface{}, owned ownedFields) bool {
+	pruned := pruneMap(live, desired, owned, unprunedFields)
+
+	liveMeta, _ := live["metadata"].(map[string]interface{})
+	desiredMeta, _ := desired["metadata"].(map[string]interface{})
+
+	for _, key := range prunedMetadata {
+		liveVal, liveFound := liveMeta[key]
+		desiredVal, desiredFound := desiredMeta[key]
+
+		if !liveFound || !desiredFound {
+			continue
+		}
+
+		if newVal, changed := pruneValue(liveVal, desiredVal, owned.field("metadata").field(key)); changed {
+			liveMeta[key] = newVal
+			pruned = true
+		}
+	}
+
+	return pruned
+}
+
+// pruneMap removes the fields from the live map which are not in the desired
+// map and which are in the owned fields, except for the skipped keys, and
+// returns true if anything was removed.
+func pruneMap(live, desired map[string]interface{}, owned ownedFields, skipped []string) bool {
+	pruned := false
+
+	for key, val := range live {
+		if containsString(skipped, key) {
+			continue
+		}
+
+		desiredVal, found := desired[key]
+		if !found {
+			if owned.field(key) != nil {
+				delete(live, key)
+				pruned = true
+			}
+
+			continue
+		}
+
+		if newVal, changed := pruneValue(val, desiredVal, owned.field(key)); changed {
+			live[key] = newVal
+			pruned = true
+		}
+	}
+
+	return pruned
+}
+
+// pruneValue returns the live value without the fields which are not in the
+// desired value and which are in the owned fields, and whether anything was
+// removed. Maps are changed in place.
+func pruneValue(live, desired interface{}, owned ownedFields) (interface{}, bool) {
+	if len(owned) == 0 {
+		return live, false
+	}
+
+	switch liveVal := live.(type) {
+	case map[string]interface{}:
+		desiredMap, ok := desired.(map[string]interface{})
+		if !ok {
+			return live, false
+		}
+
+		return liveVal, pruneMap(liveVal, desiredMap, owned, nil)
+	case []interface{}:
+		desiredList, ok := desired.([]interface{})
+		if !ok {
+			return live, false
+		}
+
+		return pruneList(liveVal, desiredList, owned)
+	default:
+		return live, false
+	}
+}
+
+// pruneList returns the live list without the items which are not in the
+// desired list and which are owned by other field managers, and whether
+// anything was removed. The items are matched with the keys in the owned
+// fields, since the list might not be in the same order as the desired list.
+func pruneList(live, desired []interface{}, owned ownedFields) ([]interface{}, bool) {
+	kept := make([]interface{}, 0, len(live))
+	pruned := false
+
+	for i, item := range live {
+		key, itemOwned := owned.item(i, item)
+		if key == "" {
+			kept = append(kept, item)
+
+			continue
+		}
+
+		desiredItem, found := desiredListItem(key, i, desired)
+		if !found {
+			pruned = true
+
+			continue
+		}
+
+		newItem, changed := pruneValue(item, desiredItem, itemOwned)
+		kept = append(kept, newItem)
+		pruned = pruned || changed
+	}
+
+	return kept, pruned
+}
+
+// desiredListItem returns the item in the desired list which is identified by
+// the key from the owned fields of a live item at the index.
+func desiredListItem(key string, index int, desired []interface{}) (interface{}, bool) {
+	if strings.HasPrefix(key, "i:") {
+		if index < len(desired) {
+			return desired[index], true
+		}
+
+		return nil, false
+	}
+
+	for i, item := range desired {
+		if itemMatches(key, i, item) {
+			return item, true
+		}
+	}
+
+	return nil, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPruneObject(t *testing.T) {
+	type test struct {
+		name       string
+		live       string
+		desired    string
+		owned      string
+		want       string
+		wantPruned bool
+	}
+
+	tests := []test{
+		{
+			name:       "unowned fields are kept",
+			live:       `{"spec":{"replicas":1,"revisionHistoryLimit":10}}`,
+			desired:    `{"spec":{"replicas":1}}`,
+			owned:      `{}`,
+			want:       `{"spec":{"replicas":1,"revisionHistoryLimit":10}}`,
+			wantPruned: false,
+		}, {
+			name:       "owned fields are removed",
+			live:       `{"spec":{"replicas":1,"paused":true},"extra":{"a":"b"}}`,
+			desired:    `{"spec":{"replicas":1}}`,
+			owned:      `{"f:spec":{"f:paused":{}},"f:extra":{}}`,
+			want:       `{"spec":{"replicas":1}}`,
+			wantPruned: true,
+		}, {
+			name: "nested metadata is not skipped",
+			live: `{"metadata":{"name":"a","labels":{"a":"b","c":"d"}},` +
+				`"spec":{"template":{"metadata":{"labels":{"app":"x","team":"y"}}}}}`,
+			desired: `{"metadata":{"name":"a","labels":{"a":"b"}},"spec":{"template":{"metadata":{"labels":{"app":"x"}}}}}`,
+			owned: `{"f:metadata":{"f:labels":{"f:c":{}}},` +
+				`"f:spec":{"f:template":{"f:metadata":{"f:labels":{"f:team":{}}}}}}`,
+			want: `{"metadata":{"name":"a","labels":{"a":"b"}},` +
+				`"spec":{"template":{"metadata":{"labels":{"app":"x"}}}}}`,
+			wantPruned: true,
+		}, {
+			name: "list items are matched by their keys",
+			live: `{"spec":{"containers":[` +
+				`{"name":"sidecar","image":"proxy"},` +
+				`{"name":"app","image":"app:v1","terminationMessagePath":"/dev/termination-log","tty":true}]}}`,
+			desired: `{"spec":{"containers":[{"name":"app","image":"app:v1"}]}}`,
+			owned: `{"f:spec":{"f:containers":{` +
+				`"k:{\"name\":\"sidecar\"}":{".":{},"f:name":{},"f:image":{}},` +
+				`"k:{\"name\":\"app\"}":{"f:tty":{}}}}}`,
+			want: `{"spec":{"containers":[` +
+				`{"name":"app","image":"app:v1","terminationMessagePath":"/dev/termination-log"}]}}`,
+			wantPruned: true,
+		}, {
+			name:       "set items are matched by their values",
+			live:       `{"spec":{"finalizers":["a","b"],"ports":[{"port":80,"protocol":"TCP"}]}}`,
+			desired:    `{"spec":{"finalizers":["a"],"ports":[{"port":80}]}}`,
+			owned:      `{"f:spec":{"f:finalizers":{"v:\"b\"":{}},"f:ports":{"k:{\"port\":80}":{"f:protocol":{}}}}}`,
+			want:       `{"spec":{"finalizers":["a"],"ports":[{"port":80}]}}`,
+			wantPruned: true,
+		},
+	}
+
+	for _, tc := range tests {
+		live := decodeJSON(t, tc.live)
+		owned := ownedFields{}
+		mergeOwned(owned, decodeJSON(t, tc.owned))
+
+		pruned := pruneObject(live, decodeJSON(t, tc.desired), owned)
+
+		if want := decodeJSON(t, tc.want); pruned != tc.wantPruned || !reflect.DeepEqual(live, want) {
+			t.Errorf("test '%v' expected: %v (%v), got: %v (%v)", tc.name, want, tc.wantPruned, live, pruned)
+		}
+	}
+}
+
+func decodeJSON(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+		t.Fatal(err)
+	}
+
+	return obj
+}
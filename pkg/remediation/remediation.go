@@ -0,0 +1,305 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remediation enforces policies by making objects on the cluster match
+// desired objects, so that policy controllers do not each need to implement
+// their own create, patch, and delete logic.
+package remediation
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// ComplianceType describes how an object on the cluster must relate to the
+// desired object for the policy to be compliant.
+type ComplianceType string
+
+const (
+	// MustHave means the object must exist, with at least the desired fields.
+	MustHave ComplianceType = "musthave"
+	// MustOnlyHave means the object must exist, with only the desired fields,
+	// apart from the fields which the API server defaults.
+	MustOnlyHave ComplianceType = "mustonlyhave"
+	// MustNotHave means the object must not exist.
+	MustNotHave ComplianceType = "mustnothave"
+)
+
+// FieldManager is the default field manager used for server-side applies.
+const FieldManager = "policy-framework"
+
+// Reasons used in the RelatedObjects returned by Remediate.
+const (
+	ReasonCreated   = "created"
+	ReasonPatched   = "patched"
+	ReasonDeleted   = "deleted"
+	ReasonUnchanged = "unchanged"
+	ReasonNotFound  = "not found"
+
+	// These are used instead when the Remediator is in dry-run mode.
+	ReasonWouldCreate = "would be created"
+	ReasonWouldPatch  = "would be patched"
+	ReasonWouldDelete = "would be deleted"
+)
+
+// Remediator makes objects on the cluster match desired objects. The client
+// needs access to get, patch, and delete the objects, and to create them when
+// they do not exist.
+type Remediator struct {
+	client.Client
+
+	// FieldManager is the field manager for the server-side applies. It
+	// defaults to the FieldManager constant when empty.
+	FieldManager string
+
+	// DryRun makes the Remediator use server-side dry-run requests, so nothing
+	// on the cluster is changed. Instead, a unified diff of the changes is put in
+	// each RelatedObject, and objects which would change are NonCompliant. It
+	// is meant for policies with the dryrun RemediationAction.
+	DryRun bool
+}
+
+// Remediate makes each of the desired objects on the cluster match the
+// compliance type, and returns a RelatedObject for each one with the outcome
+// as its reason. The objects are applied with server-side apply, forcing
+// ownership of the desired fields. For MustOnlyHave, the fields of the object
+// which are not in the desired object are then removed with an update, when
+// another field manager owns them according to the managedFields. Fields which
+// no manager owns, like the ones which the API server defaults, are kept, so an
+// object which already matches is unchanged. The status is never removed, and
+// in the metadata only extra labels and annotations are removed, when the
+// desired object has them. For MustNotHave, only the identifying fields of the
+// desired objects are used.
+//
+// In dry-run mode, the reasons say what would happen instead, like "would be
+// patched", and each RelatedObject has a Diff.
+//
+// Objects which fail to be remediated are reported as NonCompliant, and the
+// errors are returned together, after all of the objects have been attempted.
+// Controllers may want to set the Degraded condition on the policy in that
+// case.
+func (r *Remediator) Remediate(
+	ctx context.Context, complianceType ComplianceType, desired ...*unstructured.Unstructured,
+) ([]v1alpha1.RelatedObject, error) {
+	related := make([]v1alpha1.RelatedObject, 0, len(desired))
+	errs := make([]error, 0)
+
+	for _, obj := range desired {
+		var res result
+		var err error
+
+		switch complianceType {
+		case MustHave:
+			res, err = r.apply(ctx, obj, false)
+		case MustOnlyHave:
+			res, err = r.apply(ctx, obj, true)
+		case MustNotHave:
+			res, err = r.delete(ctx, obj)
+		default:
+			err = fmt.Errorf("unknown compliance type '%v'", complianceType)
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to remediate %v %v: %w", obj.GetKind(), objectName(obj), err))
+			related = append(related, relatedObject(obj, v1alpha1.NonCompliant, "error: "+err.Error(), ""))
+
+			continue
+		}
+
+		state := v1alpha1.Compliant
+		if r.DryRun && res.changed {
+			state = v1alpha1.NonCompliant
+		}
+
+		related = append(related, relatedObject(obj, state, res.reason, res.diff))
+	}
+
+	return related, utilerrors.NewAggregate(errs)
+}
+
+// result is the outcome of remediating a single object.
+type result struct {
+	reason  string
+	diff    string
+	changed bool
+}
+
+// apply does a server-side apply of the object, and returns whether it was (or
+// would be) created, patched, or unchanged. When onlyHave is true, the fields
+// which other field managers own and which are not in the object are then
+// removed from the applied object.
+func (r *Remediator) apply(ctx context.Context, obj *unstructured.Unstructured, onlyHave bool) (result, error) {
+	existing, err := r.get(ctx, obj)
+	if err != nil {
+		return result{}, err
+	}
+
+	applied := obj.DeepCopy()
+	applied.SetResourceVersion("")
+	applied.SetManagedFields(nil)
+
+	opts := []client.PatchOption{client.FieldOwner(r.fieldManager()), client.ForceOwnership}
+	if r.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	if err := r.Patch(ctx, applied, client.Apply, opts...); err != nil {
+		return result{}, err
+	}
+
+	if onlyHave {
+		if err := r.prune(ctx, applied, obj); err != nil {
+			return result{}, err
+		}
+	}
+
+	if r.DryRun {
+		diff, err := unifiedDiff(objectName(obj), existing, applied)
+		if err != nil {
+			return result{}, err
+		}
+
+		switch {
+		case existing == nil:
+			return result{reason: ReasonWouldCreate, diff: diff, changed: true}, nil
+		case diff != "":
+			return result{reason: ReasonWouldPatch, diff: diff, changed: true}, nil
+		default:
+			return result{reason: ReasonUnchanged}, nil
+		}
+	}
+
+	switch {
+	case existing == nil:
+		return result{reason: ReasonCreated, changed: true}, nil
+	case existing.GetResourceVersion() != applied.GetResourceVersion():
+		return result{reason: ReasonPatched, changed: true}, nil
+	default:
+		return result{reason: ReasonUnchanged}, nil
+	}
+}
+
+// prune removes the fields from the applied object which are not in the desired
+// object and which are owned by other field managers, with an update. Fields
+// without an owner, like the ones which the API server defaults, are kept, since
+// they would be added back by every update.
+func (r *Remediator) prune(ctx context.Context, applied, desired *unstructured.Unstructured) error {
+	owned, err := othersFields(applied, r.fieldManager())
+	if err != nil {
+		return fmt.Errorf("invalid managedFields: %w", err)
+	}
+
+	if !pruneObject(applied.Object, desired.Object, owned) {
+		return nil
+	}
+
+	opts := []client.UpdateOption{client.FieldOwner(r.fieldManager())}
+	if r.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	return r.Update(ctx, applied, opts...)
+}
+
+// delete removes the object if it exists, and returns whether it was (or would
+// be) deleted.
+func (r *Remediator) delete(ctx context.Context, obj *unstructured.Unstructured) (result, error) {
+	existing, err := r.get(ctx, obj)
+	if err != nil {
+		return result{}, err
+	}
+
+	if existing == nil {
+		return result{reason: ReasonNotFound}, nil
+	}
+
+	opts := []client.DeleteOption{client.PropagationPolicy(metav1.DeletePropagationBackground)}
+	if r.DryRun {
+		opts = append(opts, client.DryRunAll)
+	}
+
+	err = r.Delete(ctx, existing, opts...)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return result{}, err
+	}
+
+	if r.DryRun {
+		diff, err := unifiedDiff(objectName(obj), existing, nil)
+		if err != nil {
+			return result{}, err
+		}
+
+		return result{reason: ReasonWouldDelete, diff: diff, changed: true}, nil
+	}
+
+	return result{reason: ReasonDeleted, changed: true}, nil
+}
+
+// get returns the object on the cluster with the same kind, namespace, and name
+// as the given object, or nil if it does not exist.
+func (r *Remediator) get(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+func (r *Remediator) fieldManager() string {
+	if r.FieldManager == "" {
+		return FieldManager
+	}
+
+	return r.FieldManager
+}
+
+func relatedObject(
+	obj *unstructured.Unstructured, state v1alpha1.ComplianceState, reason string, diff string,
+) v1alpha1.RelatedObject {
+	return v1alpha1.RelatedObject{
+		Object: v1alpha1.ObjectRef{
+			TypeMeta: metav1.TypeMeta{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind()},
+			Metadata: v1alpha1.ObjectMetadata{Name: obj.GetName(), Namespace: obj.GetNamespace()},
+		},
+		ComplianceState: state,
+		Reason:          v1alpha1.NonEmptyString(reason),
+		Diff:            diff,
+	}
+}
+
+func objectName(obj client.Object) string {
+	if obj.GetNamespace() == "" {
+		return obj.GetName()
+	}
+
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
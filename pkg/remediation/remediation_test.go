@@ -0,0 +1,449 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remediation
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// applyClient handles server-side apply patches, which the fake client does not
+// support, by merging the fields of the applied object into the existing object
+// like a server-side apply would, or creating it. With a dry-run, the merged
+// object is only returned.
+type applyClient struct {
+	client.Client
+}
+
+func (c applyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	applied := obj.(*unstructured.Unstructured)
+
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	dryRun := len(patchOpts.DryRun) != 0
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(applied.GroupVersionKind())
+
+	err := c.Get(ctx, client.ObjectKeyFromObject(applied), existing)
+	if apierrors.IsNotFound(err) {
+		if dryRun {
+			return nil
+		}
+
+		return c.Create(ctx, applied)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	merged := existing.DeepCopy()
+	for key, val := range applied.Object {
+		if key != "metadata" {
+			merged.Object[key] = mergeValues(merged.Object[key], val)
+		}
+	}
+
+	if !dryRun && !reflect.DeepEqual(merged, existing) {
+		if err := c.Update(ctx, merged); err != nil {
+			return err
+		}
+	}
+
+	merged.DeepCopyInto(applied)
+
+	return nil
+}
+
+// mergeValues merges maps recursively, and otherwise returns the applied value.
+func mergeValues(existing, applied interface{}) interface{} {
+	existingMap, ok := existing.(map[string]interface{})
+	if !ok {
+		return applied
+	}
+
+	appliedMap, ok := applied.(map[string]interface{})
+	if !ok {
+		return applied
+	}
+
+	for key, val := range appliedMap {
+		existingMap[key] = mergeValues(existingMap[key], val)
+	}
+
+	return existingMap
+}
+
+func configMap(name string, data map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+	}}
+
+	if data != nil {
+		obj.Object["data"] = data
+	}
+
+	return obj
+}
+
+// managedBy returns managedFields where the manager owns the fields, which are
+// given as FieldsV1 JSON.
+func managedBy(manager string, fields string) []metav1.ManagedFieldsEntry {
+	return []metav1.ManagedFieldsEntry{{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}}
+}
+
+func TestRemediate(t *testing.T) {
+	existing := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "same", Namespace: "default"},
+			Data:       map[string]string{"foo": "bar"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "different", Namespace: "default"},
+			Data:       map[string]string{"foo": "baz"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "unwanted", Namespace: "default"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "extra", Namespace: "default", ManagedFields: managedBy("kubectl", `{"f:data":{"f:extra":{}}}`),
+			},
+			Data: map[string]string{"foo": "baz", "extra": "x"},
+		},
+	}
+
+	type test struct {
+		name           string
+		complianceType ComplianceType
+		desired        *unstructured.Unstructured
+		wantState      v1alpha1.ComplianceState
+		wantReason     v1alpha1.NonEmptyString
+		wantData       map[string]string
+		wantErr        bool
+	}
+
+	tests := []test{
+		{
+			name:           "musthave missing object",
+			complianceType: MustHave,
+			desired:        configMap("missing", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonCreated,
+		}, {
+			name:           "musthave matching object",
+			complianceType: MustHave,
+			desired:        configMap("same", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonUnchanged,
+		}, {
+			name:           "mustonlyhave different object",
+			complianceType: MustOnlyHave,
+			desired:        configMap("different", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonPatched,
+		}, {
+			name:           "musthave object with an extra key",
+			complianceType: MustHave,
+			desired:        configMap("extra", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonPatched,
+			wantData:       map[string]string{"foo": "bar", "extra": "x"},
+		}, {
+			name:           "mustonlyhave object with an extra key",
+			complianceType: MustOnlyHave,
+			desired:        configMap("extra", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonPatched,
+			wantData:       map[string]string{"foo": "bar"},
+		}, {
+			name:           "mustonlyhave object with only an extra key",
+			complianceType: MustOnlyHave,
+			desired:        configMap("extra", map[string]interface{}{"foo": "baz"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonPatched,
+			wantData:       map[string]string{"foo": "baz"},
+		}, {
+			name:           "mustnothave existing object",
+			complianceType: MustNotHave,
+			desired:        configMap("unwanted", nil),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonDeleted,
+		}, {
+			name:           "mustnothave missing object",
+			complianceType: MustNotHave,
+			desired:        configMap("gone", nil),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonNotFound,
+		}, {
+			name:           "unknown compliance type",
+			complianceType: "musthavesomething",
+			desired:        configMap("same", nil),
+			wantState:      v1alpha1.NonCompliant,
+			wantReason:     "error: unknown compliance type 'musthavesomething'",
+			wantErr:        true,
+		},
+	}
+
+	for _, tc := range tests {
+		r := &Remediator{Client: applyClient{fake.NewClientBuilder().WithObjects(existing...).Build()}}
+
+		related, err := r.Remediate(context.TODO(), tc.complianceType, tc.desired)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		if len(related) != 1 {
+			t.Errorf("test '%v' expected 1 related object, got: %v", tc.name, related)
+
+			continue
+		}
+
+		if related[0].ComplianceState != tc.wantState || related[0].Reason != tc.wantReason {
+			t.Errorf("test '%v' expected: %v (%v), got: %v (%v)", tc.name,
+				tc.wantState, tc.wantReason, related[0].ComplianceState, related[0].Reason)
+		}
+
+		if related[0].Object.Kind != "ConfigMap" || related[0].Object.Metadata.Name != tc.desired.GetName() {
+			t.Errorf("test '%v' reported the wrong object: %+v", tc.name, related[0].Object)
+		}
+
+		got := &corev1.ConfigMap{}
+		err = r.Get(context.TODO(), client.ObjectKeyFromObject(tc.desired), got)
+
+		switch tc.complianceType {
+		case MustHave, MustOnlyHave:
+			wantData := tc.wantData
+			if wantData == nil {
+				wantData = map[string]string{"foo": "bar"}
+			}
+
+			if err != nil || !reflect.DeepEqual(got.Data, wantData) {
+				t.Errorf("test '%v' expected the object to be applied, got: %+v (error: %v)", tc.name, got, err)
+			}
+		case MustNotHave:
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("test '%v' expected the object to be deleted, got error: %v", tc.name, err)
+			}
+		}
+	}
+}
+
+func TestRemediateDefaultedFields(t *testing.T) {
+	immutable := false
+
+	// The immutable field has no owner, like a field which the API server
+	// defaulted, and the data is owned by the Remediator.
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "defaulted", Namespace: "default", ManagedFields: managedBy(FieldManager, `{"f:data":{"f:foo":{}}}`),
+		},
+		Data:      map[string]string{"foo": "bar"},
+		Immutable: &immutable,
+	}
+
+	r := &Remediator{Client: applyClient{fake.NewClientBuilder().WithObjects(existing).Build()}}
+
+	desired := configMap("defaulted", map[string]interface{}{"foo": "bar"})
+
+	for i := 0; i < 2; i++ {
+		related, err := r.Remediate(context.TODO(), MustOnlyHave, desired)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if related[0].ComplianceState != v1alpha1.Compliant || related[0].Reason != ReasonUnchanged {
+			t.Errorf("test 'remediation %v' expected: %v (%v), got: %v (%v)", i,
+				v1alpha1.Compliant, ReasonUnchanged, related[0].ComplianceState, related[0].Reason)
+		}
+	}
+
+	got := &corev1.ConfigMap{}
+	if err := r.Get(context.TODO(), client.ObjectKeyFromObject(existing), got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Immutable == nil {
+		t.Errorf("expected the defaulted field to be kept, got: %+v", got)
+	}
+}
+
+func TestRemediateDryRun(t *testing.T) {
+	existing := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "same", Namespace: "default"},
+			Data:       map[string]string{"foo": "bar"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "different", Namespace: "default"},
+			Data:       map[string]string{"foo": "baz"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "extra", Namespace: "default", ManagedFields: managedBy("kubectl", `{"f:data":{"f:extra":{}}}`),
+			},
+			Data: map[string]string{"foo": "bar", "extra": "x"},
+		},
+	}
+
+	type test struct {
+		name           string
+		complianceType ComplianceType
+		desired        *unstructured.Unstructured
+		wantState      v1alpha1.ComplianceState
+		wantReason     v1alpha1.NonEmptyString
+		wantDiff       string
+	}
+
+	tests := []test{
+		{
+			name:           "missing object",
+			complianceType: MustHave,
+			desired:        configMap("missing", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.NonCompliant,
+			wantReason:     ReasonWouldCreate,
+			wantDiff: `--- live/default/missing
++++ dryrun/default/missing
+@@ -0,0 +1,7 @@
++apiVersion: v1
++data:
++  foo: bar
++kind: ConfigMap
++metadata:
++  name: missing
++  namespace: default
+`,
+		}, {
+			name:           "matching object",
+			complianceType: MustHave,
+			desired:        configMap("same", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonUnchanged,
+		}, {
+			name:           "different object",
+			complianceType: MustOnlyHave,
+			desired:        configMap("different", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.NonCompliant,
+			wantReason:     ReasonWouldPatch,
+			wantDiff: `--- live/default/different
++++ dryrun/default/different
+@@ -1,6 +1,6 @@
+ apiVersion: v1
+ data:
+-  foo: baz
++  foo: bar
+ kind: ConfigMap
+ metadata:
+   name: different
+`,
+		}, {
+			name:           "object with an extra key",
+			complianceType: MustHave,
+			desired:        configMap("extra", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.Compliant,
+			wantReason:     ReasonUnchanged,
+		}, {
+			name:           "object with an extra key which must only have",
+			complianceType: MustOnlyHave,
+			desired:        configMap("extra", map[string]interface{}{"foo": "bar"}),
+			wantState:      v1alpha1.NonCompliant,
+			wantReason:     ReasonWouldPatch,
+			wantDiff: `--- live/default/extra
++++ dryrun/default/extra
+@@ -1,6 +1,5 @@
+ apiVersion: v1
+ data:
+-  extra: x
+   foo: bar
+ kind: ConfigMap
+ metadata:
+`,
+		}, {
+			name:           "unwanted object",
+			complianceType: MustNotHave,
+			desired:        configMap("same", nil),
+			wantState:      v1alpha1.NonCompliant,
+			wantReason:     ReasonWouldDelete,
+			wantDiff: `--- live/default/same
++++ dryrun/default/same
+@@ -1,7 +0,0 @@
+-apiVersion: v1
+-data:
+-  foo: bar
+-kind: ConfigMap
+-metadata:
+-  name: same
+-  namespace: default
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		c := applyClient{fake.NewClientBuilder().WithObjects(existing...).Build()}
+		r := &Remediator{Client: c, DryRun: true}
+
+		related, err := r.Remediate(context.TODO(), tc.complianceType, tc.desired)
+		if err != nil {
+			t.Errorf("test '%v' unexpected error: %v", tc.name, err)
+
+			continue
+		}
+
+		if related[0].ComplianceState != tc.wantState || related[0].Reason != tc.wantReason {
+			t.Errorf("test '%v' expected: %v (%v), got: %v (%v)", tc.name,
+				tc.wantState, tc.wantReason, related[0].ComplianceState, related[0].Reason)
+		}
+
+		if related[0].Diff != tc.wantDiff {
+			t.Errorf("test '%v' expected diff:\n%v\ngot:\n%v", tc.name, tc.wantDiff, related[0].Diff)
+		}
+
+		// Nothing on the cluster should have changed
+		for _, obj := range existing {
+			got := &corev1.ConfigMap{}
+			if err := c.Get(context.TODO(), client.ObjectKeyFromObject(obj), got); err != nil {
+				t.Errorf("test '%v' changed the cluster: %v", tc.name, err)
+			} else if !reflect.DeepEqual(got.Data, obj.(*corev1.ConfigMap).Data) {
+				t.Errorf("test '%v' changed the cluster: %v", tc.name, got.Data)
+			}
+		}
+	}
+}
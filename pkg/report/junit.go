@@ -0,0 +1,134 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite is a group of policies in a JUnit XML report.
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a policy in a JUnit XML report.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+// junitMessage is the failure or skipped element of a test case.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// junitName is the name of the root element of a JUnit XML report.
+const junitName string = "policy compliance"
+
+// WriteJUnit writes the report as JUnit XML. Each group is a test suite named
+// like "<severity>/<namespace>", and each policy is a test case, which fails
+// when Policy.Failed returns true. Policies with an unknown compliance which
+// are not failed are skipped. The failure text lists the related objects which
+// are not compliant.
+func WriteJUnit(w io.Writer, report *Report) error {
+	suites := junitTestSuites{Name: junitName, Suites: make([]junitTestSuite, 0, len(report.Groups))}
+
+	for _, group := range report.Groups {
+		suite := junitTestSuite{Name: group.Name(), Cases: make([]junitTestCase, 0, len(group.Policies))}
+
+		for _, p := range group.Policies {
+			tc := junitTestCase{Name: policyName(p), ClassName: group.Name()}
+
+			switch {
+			case p.Failed():
+				tc.Failure = &junitMessage{
+					Message: junitFailureMessage(p),
+					Type:    string(p.ComplianceState),
+					Text:    strings.Join(violations(p), "\n"),
+				}
+				suite.Failures++
+			case p.ComplianceState != v1alpha1.Compliant:
+				tc.Skipped = &junitMessage{Message: p.Message}
+				suite.Skipped++
+			}
+
+			suite.Tests++
+			suite.Cases = append(suite.Cases, tc)
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%v%s\n", xml.Header, out)
+
+	return err
+}
+
+// junitFailureMessage returns the message of the policy's Compliant condition,
+// or its compliance if it has no message.
+func junitFailureMessage(p Policy) string {
+	if p.Message != "" {
+		return p.Message
+	}
+
+	return "the policy is " + string(p.ComplianceState)
+}
+
+// violations describes the related objects of the policy which are not
+// compliant, one per line.
+func violations(p Policy) []string {
+	lines := make([]string, 0, len(p.RelatedObjects))
+
+	for _, related := range p.RelatedObjects {
+		if related.ComplianceState != v1alpha1.Compliant {
+			lines = append(lines, relatedObjectString(related))
+		}
+	}
+
+	return lines
+}
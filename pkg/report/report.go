@@ -0,0 +1,329 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report builds compliance reports from the policies on a cluster, and
+// writes them in formats which other tools understand: JSON, CSV, JUnit XML for
+// CI systems, and SARIF for code scanning tools. Policies in a report are
+// grouped by their severity and namespace.
+package report
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+)
+
+// Policy is the compliance of a single policy in a report.
+type Policy struct {
+	APIVersion        string                     `json:"apiVersion"`
+	Kind              string                     `json:"kind"`
+	Metadata          v1alpha1.ObjectMetadata    `json:"metadata"`
+	Severity          v1alpha1.Severity          `json:"severity,omitempty"`
+	RemediationAction v1alpha1.RemediationAction `json:"remediationAction,omitempty"`
+	ComplianceState   v1alpha1.ComplianceState   `json:"compliant,omitempty"`
+	// Reason and Message are from the Compliant condition of the policy.
+	Reason         string                   `json:"reason,omitempty"`
+	Message        string                   `json:"message,omitempty"`
+	RelatedObjects []v1alpha1.RelatedObject `json:"relatedObjects,omitempty"`
+}
+
+// Failed returns true if the policy is NonCompliant, or if its compliance is
+// unknown and UnknownCompliancyMeansViolation is set.
+func (p Policy) Failed() bool {
+	switch p.ComplianceState {
+	case v1alpha1.Compliant:
+		return false
+	case v1alpha1.NonCompliant:
+		return true
+	default:
+		return v1alpha1.UnknownCompliancyMeansViolation
+	}
+}
+
+// Summary counts the policies in a report or group by their compliance.
+type Summary struct {
+	Total        int `json:"total"`
+	Compliant    int `json:"compliant"`
+	NonCompliant int `json:"noncompliant"`
+	Unknown      int `json:"unknown"`
+}
+
+// add counts the policy in the summary.
+func (s *Summary) add(p Policy) {
+	s.Total++
+
+	switch p.ComplianceState {
+	case v1alpha1.Compliant:
+		s.Compliant++
+	case v1alpha1.NonCompliant:
+		s.NonCompliant++
+	default:
+		s.Unknown++
+	}
+}
+
+// Group is the policies in a report with the same severity and namespace.
+type Group struct {
+	Severity  v1alpha1.Severity `json:"severity,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	Summary
+	Policies []Policy `json:"policies"`
+}
+
+// Name identifies the group, as "<severity>/<namespace>". Policies without a
+// severity are in the "unspecified" severity, and cluster-scoped policies are
+// in the "cluster" namespace.
+func (g Group) Name() string {
+	severity := string(g.Severity)
+	if severity == "" {
+		severity = "unspecified"
+	}
+
+	namespace := g.Namespace
+	if namespace == "" {
+		namespace = "cluster"
+	}
+
+	return severity + "/" + namespace
+}
+
+// Report is the compliance of a set of policies.
+type Report struct {
+	Summary Summary `json:"summary"`
+	// Groups are ordered from the most severe to the least severe, and then by
+	// namespace.
+	Groups []Group `json:"groups"`
+}
+
+// severityOrder is the position of each severity in a report. Policies with
+// other severities, or none, come last.
+var severityOrder = map[v1alpha1.Severity]int{
+	v1alpha1.SeverityCritical: 0,
+	v1alpha1.SeverityHigh:     1,
+	v1alpha1.SeverityMedium:   2,
+	v1alpha1.SeverityLow:      3,
+}
+
+// severityRank returns the position of the severity in a report.
+func severityRank(severity v1alpha1.Severity) int {
+	if rank, found := severityOrder[severity]; found {
+		return rank
+	}
+
+	return len(severityOrder)
+}
+
+// New returns a report of the policies, grouped by severity and namespace.
+// Within a group, the policies are sorted by kind and name.
+func New(policies []Policy) *Report {
+	report := &Report{Groups: make([]Group, 0)}
+	groups := make(map[[2]string]int)
+
+	for _, p := range policies {
+		key := [2]string{string(p.Severity), p.Metadata.Namespace}
+
+		i, found := groups[key]
+		if !found {
+			i = len(report.Groups)
+			groups[key] = i
+			report.Groups = append(report.Groups, Group{
+				Severity:  p.Severity,
+				Namespace: p.Metadata.Namespace,
+				Policies:  make([]Policy, 0),
+			})
+		}
+
+		report.Groups[i].Policies = append(report.Groups[i].Policies, p)
+		report.Groups[i].Summary.add(p)
+		report.Summary.add(p)
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) < severityRank(b.Severity)
+		}
+
+		if a.Severity != b.Severity {
+			return a.Severity < b.Severity
+		}
+
+		return a.Namespace < b.Namespace
+	})
+
+	for _, group := range report.Groups {
+		policies := group.Policies
+		sort.Slice(policies, func(i, j int) bool {
+			if policies[i].Kind != policies[j].Kind {
+				return policies[i].Kind < policies[j].Kind
+			}
+
+			return policies[i].Metadata.Name < policies[j].Metadata.Name
+		})
+	}
+
+	return report
+}
+
+// PolicyFromTyper returns the compliance of the policy for a report. The kind is
+// passed separately because typed objects fetched with a client usually do not
+// have their TypeMeta set.
+func PolicyFromTyper(gvk schema.GroupVersionKind, policy v1alpha1.PolicyTyper) Policy {
+	spec := policy.PolicySpec()
+	status := policy.PolicyStatus()
+
+	p := Policy{
+		APIVersion:        gvk.GroupVersion().String(),
+		Kind:              gvk.Kind,
+		Metadata:          v1alpha1.ObjectMetadata{Name: policy.GetName(), Namespace: policy.GetNamespace()},
+		Severity:          spec.GetSeverity(),
+		RemediationAction: spec.GetRemediationAction(),
+		ComplianceState:   status.ComplianceState,
+		RelatedObjects:    status.RelatedObjects,
+	}
+
+	if cond := meta.FindStatusCondition(status.Conditions, v1alpha1.ComplianceConditionType); cond != nil {
+		p.Reason = cond.Reason
+		p.Message = cond.Message
+	}
+
+	return p
+}
+
+// PolicyKinds returns the kinds in the scheme whose types implement
+// PolicyTyper, and which have a list type registered. When a kind is
+// registered in more than one version, only the preferred version is used.
+func PolicyKinds(scheme *runtime.Scheme) []schema.GroupVersionKind {
+	policyTyper := reflect.TypeOf((*v1alpha1.PolicyTyper)(nil)).Elem()
+	found := make(map[schema.GroupKind][]string)
+
+	for gvk, typ := range scheme.AllKnownTypes() {
+		if gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+
+		if !reflect.PtrTo(typ).Implements(policyTyper) {
+			continue
+		}
+
+		if !scheme.Recognizes(gvk.GroupVersion().WithKind(gvk.Kind + "List")) {
+			continue
+		}
+
+		found[gvk.GroupKind()] = append(found[gvk.GroupKind()], gvk.Version)
+	}
+
+	kinds := make([]schema.GroupVersionKind, 0, len(found))
+
+	for gk, versions := range found {
+		kinds = append(kinds, gk.WithVersion(preferredVersion(scheme, gk.Group, versions)))
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+
+	return kinds
+}
+
+// preferredVersion returns the version which the scheme prioritizes for the
+// group, out of the versions, or the first one in sorted order if the scheme
+// does not prioritize any of them.
+func preferredVersion(scheme *runtime.Scheme, group string, versions []string) string {
+	for _, gv := range scheme.PrioritizedVersionsForGroup(group) {
+		for _, version := range versions {
+			if gv.Version == version {
+				return version
+			}
+		}
+	}
+
+	sort.Strings(versions)
+
+	return versions[0]
+}
+
+// Collect returns a report of the policies of every kind in the scheme which
+// implements PolicyTyper (see PolicyKinds). The list options can limit which
+// policies are included, for example to a namespace. The client.Reader needs
+// access to list each kind of policy.
+func Collect(
+	ctx context.Context, r client.Reader, scheme *runtime.Scheme, opts ...client.ListOption,
+) (*Report, error) {
+	return CollectKinds(ctx, r, PolicyKinds(scheme), opts...)
+}
+
+// CollectKinds returns a report of the policies of the kinds. The kinds do not
+// need to be registered in the client's scheme, since the policies are listed
+// as unstructured objects, and only their PolicyTypeSpec and PolicyTypeStatus
+// fields are used. Kinds listed at the v1beta1 version are decoded with the
+// v1beta1 fields, like the compliance state, and every other version with the
+// v1alpha1 fields.
+func CollectKinds(
+	ctx context.Context, r client.Reader, kinds []schema.GroupVersionKind, opts ...client.ListOption,
+) (*Report, error) {
+	policies := make([]Policy, 0)
+
+	for _, gvk := range kinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		if err := r.List(ctx, list, opts...); err != nil {
+			return nil, fmt.Errorf("unable to list %v: %w", gvk.Kind, err)
+		}
+
+		for _, item := range list.Items {
+			policy, err := decodePolicy(gvk, item.Object)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v %v/%v: %w", gvk.Kind, item.GetNamespace(), item.GetName(), err)
+			}
+
+			policies = append(policies, PolicyFromTyper(gvk, policy))
+		}
+	}
+
+	return New(policies), nil
+}
+
+// decodePolicy returns the PolicyTypeSpec and PolicyTypeStatus fields of the
+// unstructured policy, decoded with the framework types matching the version.
+func decodePolicy(gvk schema.GroupVersionKind, obj map[string]interface{}) (*v1alpha1.PolicyType, error) {
+	policy := &v1alpha1.PolicyType{}
+
+	if gvk.Version != v1beta1.GroupVersion.Version {
+		err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, policy)
+
+		return policy, err
+	}
+
+	hub := &v1beta1.PolicyType{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, hub); err != nil {
+		return nil, err
+	}
+
+	return policy, policy.ConvertFrom(hub)
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+)
+
+func policy(kind, namespace, name string, severity v1alpha1.Severity, state v1alpha1.ComplianceState) Policy {
+	return Policy{
+		APIVersion:      v1alpha1.GroupVersion.String(),
+		Kind:            kind,
+		Metadata:        v1alpha1.ObjectMetadata{Name: name, Namespace: namespace},
+		Severity:        severity,
+		ComplianceState: state,
+	}
+}
+
+func TestNew(t *testing.T) {
+	report := New([]Policy{
+		policy("PolicyType", "default", "b", v1alpha1.SeverityLow, v1alpha1.Compliant),
+		policy("PolicyType", "default", "a", v1alpha1.SeverityLow, v1alpha1.NonCompliant),
+		policy("PolicyType", "prod", "c", "", v1alpha1.UnknownCompliancy),
+		policy("PolicyType", "prod", "d", v1alpha1.SeverityCritical, v1alpha1.NonCompliant),
+		policy("MockPolicy", "default", "e", v1alpha1.SeverityLow, v1alpha1.Compliant),
+		policy("PolicyType", "", "f", v1alpha1.SeverityCritical, v1alpha1.Compliant),
+	})
+
+	wantSummary := Summary{Total: 6, Compliant: 3, NonCompliant: 2, Unknown: 1}
+	if report.Summary != wantSummary {
+		t.Errorf("expected summary: %+v, got: %+v", wantSummary, report.Summary)
+	}
+
+	wantGroups := []string{"critical/cluster", "critical/prod", "low/default", "unspecified/prod"}
+	gotGroups := make([]string, len(report.Groups))
+
+	for i, group := range report.Groups {
+		gotGroups[i] = group.Name()
+	}
+
+	if !reflect.DeepEqual(gotGroups, wantGroups) {
+		t.Errorf("expected groups: %v, got: %v", wantGroups, gotGroups)
+	}
+
+	wantPolicies := []string{"MockPolicy default/e", "PolicyType default/a", "PolicyType default/b"}
+	gotPolicies := make([]string, 0)
+
+	for _, p := range report.Groups[2].Policies {
+		gotPolicies = append(gotPolicies, policyName(p))
+	}
+
+	if !reflect.DeepEqual(gotPolicies, wantPolicies) {
+		t.Errorf("expected low/default policies: %v, got: %v", wantPolicies, gotPolicies)
+	}
+
+	wantLow := Summary{Total: 3, Compliant: 2, NonCompliant: 1}
+	if report.Groups[2].Summary != wantLow {
+		t.Errorf("expected low/default summary: %+v, got: %+v", wantLow, report.Groups[2].Summary)
+	}
+}
+
+func TestPolicyFailed(t *testing.T) {
+	type test struct {
+		state                v1alpha1.ComplianceState
+		unknownMeansViolated bool
+		want                 bool
+	}
+
+	tests := []test{
+		{state: v1alpha1.Compliant, unknownMeansViolated: true, want: false},
+		{state: v1alpha1.NonCompliant, unknownMeansViolated: false, want: true},
+		{state: v1alpha1.UnknownCompliancy, unknownMeansViolated: true, want: true},
+		{state: v1alpha1.UnknownCompliancy, unknownMeansViolated: false, want: false},
+		{state: "", unknownMeansViolated: true, want: true},
+	}
+
+	defer func(orig bool) { v1alpha1.UnknownCompliancyMeansViolation = orig }(v1alpha1.UnknownCompliancyMeansViolation)
+
+	for _, tc := range tests {
+		v1alpha1.UnknownCompliancyMeansViolation = tc.unknownMeansViolated
+
+		got := policy("PolicyType", "default", "test", "", tc.state).Failed()
+		if got != tc.want {
+			t.Errorf("test '%v/%v' expected: %v, got: %v", tc.state, tc.unknownMeansViolated, tc.want, got)
+		}
+	}
+}
+
+func testScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+
+	return scheme
+}
+
+func TestPolicyKinds(t *testing.T) {
+	want := []schema.GroupVersionKind{v1alpha1.GroupVersion.WithKind("PolicyType")}
+
+	got := PolicyKinds(testScheme())
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected: %v, got: %v", want, got)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	scheme := testScheme()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(v1alpha1.GroupVersion.WithKind("PolicyType"), meta.RESTScopeNamespace)
+
+	related := []v1alpha1.RelatedObject{{
+		Object: v1alpha1.ObjectRef{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			Metadata: v1alpha1.ObjectMetadata{Name: "settings", Namespace: "default"},
+		},
+		ComplianceState: v1alpha1.NonCompliant,
+		Reason:          "Resource not found but should exist",
+	}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		&v1alpha1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: "noncompliant", Namespace: "default"},
+			Spec:       v1alpha1.PolicyTypeSpec{Severity: "High", RemediationAction: "Inform"},
+			Status: v1alpha1.PolicyTypeStatus{
+				ComplianceState: v1alpha1.NonCompliant,
+				RelatedObjects:  related,
+				Conditions: []metav1.Condition{{
+					Type:    v1alpha1.ComplianceConditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  v1alpha1.ReasonViolationsFound,
+					Message: "settings was not found",
+				}},
+			},
+		},
+		&v1alpha1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: "compliant", Namespace: "prod"},
+			Spec:       v1alpha1.PolicyTypeSpec{Severity: "low"},
+			Status:     v1alpha1.PolicyTypeStatus{ComplianceState: v1alpha1.Compliant},
+		},
+	).Build()
+
+	report, err := Collect(context.TODO(), c, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got: %v", len(report.Groups))
+	}
+
+	want := Policy{
+		APIVersion:        v1alpha1.GroupVersion.String(),
+		Kind:              "PolicyType",
+		Metadata:          v1alpha1.ObjectMetadata{Name: "noncompliant", Namespace: "default"},
+		Severity:          v1alpha1.SeverityHigh,
+		RemediationAction: v1alpha1.RemediationInform,
+		ComplianceState:   v1alpha1.NonCompliant,
+		Reason:            v1alpha1.ReasonViolationsFound,
+		Message:           "settings was not found",
+		RelatedObjects:    related,
+	}
+
+	if got := report.Groups[0].Policies[0]; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected: %+v, got: %+v", want, got)
+	}
+
+	report, err = Collect(context.TODO(), c, scheme, client.InNamespace("prod"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Summary.Total != 1 || report.Groups[0].Name() != "low/prod" {
+		t.Errorf("expected only the low/prod policy, got: %+v", report.Groups)
+	}
+}
+
+func TestCollectKindsV1beta1(t *testing.T) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
+
+	gvk := v1beta1.GroupVersion.WithKind("PolicyType")
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		&v1beta1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: "noncompliant", Namespace: "default"},
+			Spec:       v1beta1.PolicyTypeSpec{Severity: v1beta1.SeverityHigh},
+			Status: v1beta1.PolicyTypeStatus{
+				ComplianceState: v1beta1.NonCompliant,
+				RelatedObjects: []v1beta1.RelatedObject{{
+					Object: v1beta1.ObjectRef{
+						TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+						Metadata: v1beta1.ObjectMetadata{Name: "settings", Namespace: "default"},
+					},
+					ComplianceState: v1beta1.NonCompliant,
+					Reason:          "Resource not found but should exist",
+				}},
+			},
+		},
+	).Build()
+
+	report, err := CollectKinds(context.TODO(), c, []schema.GroupVersionKind{gvk})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Summary != (Summary{Total: 1, NonCompliant: 1}) {
+		t.Fatalf("expected 1 noncompliant policy, got: %+v", report.Summary)
+	}
+
+	got := report.Groups[0].Policies[0]
+	if got.APIVersion != v1beta1.GroupVersion.String() || got.Severity != v1alpha1.SeverityHigh {
+		t.Errorf("expected a high severity %v policy, got: %+v", v1beta1.GroupVersion, got)
+	}
+
+	if len(got.RelatedObjects) != 1 || got.RelatedObjects[0].ComplianceState != v1alpha1.NonCompliant {
+		t.Errorf("expected 1 noncompliant related object, got: %+v", got.RelatedObjects)
+	}
+}
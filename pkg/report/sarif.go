@@ -0,0 +1,203 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"io"
+	"strings"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// ToolName is the name of the tool in SARIF reports.
+var ToolName = "policy-framework"
+
+// sarifSchema and sarifVersion identify the version of SARIF that is written.
+const (
+	sarifSchema  string = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion string = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifProperties    `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifProperties struct {
+	Severity  string `json:"severity,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Group     string `json:"group"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Kind       string          `json:"kind"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifLevel returns the SARIF level for violations of a policy with the
+// severity.
+func sarifLevel(severity v1alpha1.Severity) string {
+	switch severity {
+	case v1alpha1.SeverityCritical, v1alpha1.SeverityHigh:
+		return "error"
+	case v1alpha1.SeverityLow:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+// WriteSARIF writes the report as a SARIF 2.1.0 log with a single run. Each
+// policy is a rule, whose level depends on the policy's severity: critical
+// and high are errors, medium is a warning, and low is a note. Each failed
+// policy (see Policy.Failed) has a result for each related object which is not
+// compliant, located at the object, or a single result located at the policy
+// when it has no such related objects. Policies which did not fail have no
+// results.
+func WriteSARIF(w io.Writer, report *Report) error {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: ToolName, Rules: make([]sarifRule, 0)}},
+		Results: make([]sarifResult, 0),
+	}
+
+	for _, group := range report.Groups {
+		for _, p := range group.Policies {
+			properties := sarifProperties{
+				Severity:  string(p.Severity),
+				Namespace: p.Metadata.Namespace,
+				Group:     group.Name(),
+			}
+
+			ruleIndex := len(run.Tool.Driver.Rules)
+			rule := sarifRule{
+				ID:                   sarifID(p.APIVersion, p.Kind, p.Metadata),
+				Name:                 p.Metadata.Name,
+				ShortDescription:     sarifMessage{Text: policyName(p)},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(p.Severity)},
+				Properties:           properties,
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+
+			if !p.Failed() {
+				continue
+			}
+
+			result := func(msg string, location sarifLogicalLocation) sarifResult {
+				return sarifResult{
+					RuleID:     rule.ID,
+					RuleIndex:  ruleIndex,
+					Kind:       "fail",
+					Level:      rule.DefaultConfiguration.Level,
+					Message:    sarifMessage{Text: msg},
+					Locations:  []sarifLocation{{LogicalLocations: []sarifLogicalLocation{location}}},
+					Properties: properties,
+				}
+			}
+
+			found := false
+
+			for _, related := range p.RelatedObjects {
+				if related.ComplianceState == v1alpha1.Compliant {
+					continue
+				}
+
+				found = true
+				run.Results = append(run.Results, result(
+					policyName(p)+": "+relatedObjectString(related),
+					sarifLogicalLocation{
+						Name: related.Object.Metadata.Name,
+						FullyQualifiedName: sarifID(
+							related.Object.APIVersion, related.Object.Kind, related.Object.Metadata),
+						Kind: "resource",
+					}))
+			}
+
+			if !found {
+				msg := policyName(p) + " is " + string(p.ComplianceState)
+				if p.Message != "" {
+					msg += ": " + p.Message
+				}
+
+				run.Results = append(run.Results, result(msg, sarifLogicalLocation{
+					Name:               p.Metadata.Name,
+					FullyQualifiedName: rule.ID,
+					Kind:               "resource",
+				}))
+			}
+		}
+	}
+
+	return writeIndentedJSON(w, sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}})
+}
+
+// sarifID identifies an object in a SARIF log, as
+// "<apiVersion>/<kind>/<namespace>/<name>", leaving out the namespace for
+// cluster-scoped objects.
+func sarifID(apiVersion, kind string, metadata v1alpha1.ObjectMetadata) string {
+	parts := []string{apiVersion, kind}
+	if metadata.Namespace != "" {
+		parts = append(parts, metadata.Namespace)
+	}
+
+	return strings.Join(append(parts, metadata.Name), "/")
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// FormatJSON writes the report as a JSON object.
+const FormatJSON string = "json"
+
+// FormatCSV writes the report as CSV, with a row for each related object of
+// each policy, or a single row for a policy without related objects.
+const FormatCSV string = "csv"
+
+// FormatJUnit writes the report as JUnit XML, with a test suite for each group
+// and a test case for each policy, so that CI systems can show the policies
+// which are not compliant as failed tests.
+const FormatJUnit string = "junit"
+
+// FormatSARIF writes the report as a SARIF 2.1.0 log, with a rule for each
+// policy and a result for each violation, for code scanning tools.
+const FormatSARIF string = "sarif"
+
+// Formats are the formats which Write supports.
+var Formats = []string{FormatJSON, FormatCSV, FormatJUnit, FormatSARIF}
+
+// Write writes the report to the writer in the format, which must be one of
+// the Formats.
+func Write(w io.Writer, report *Report, format string) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, report)
+	case FormatCSV:
+		return WriteCSV(w, report)
+	case FormatJUnit:
+		return WriteJUnit(w, report)
+	case FormatSARIF:
+		return WriteSARIF(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+// WriteJSON writes the report as an indented JSON object.
+func WriteJSON(w io.Writer, report *Report) error {
+	return writeIndentedJSON(w, report)
+}
+
+// writeIndentedJSON writes the value as indented JSON, followed by a newline.
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(out))
+
+	return err
+}
+
+// csvHeader is the first row of a CSV report.
+var csvHeader = []string{
+	"severity", "namespace", "apiVersion", "kind", "name", "remediationAction", "compliant", "reason", "message",
+	"relatedApiVersion", "relatedKind", "relatedNamespace", "relatedName", "relatedCompliant", "relatedReason",
+}
+
+// WriteCSV writes the report as CSV, in the order of its groups. Each row has
+// the columns in csvHeader, with a row for each related object of each policy.
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, group := range report.Groups {
+		for _, p := range group.Policies {
+			policyColumns := []string{
+				string(p.Severity), p.Metadata.Namespace, p.APIVersion, p.Kind, p.Metadata.Name,
+				string(p.RemediationAction), string(p.ComplianceState), p.Reason, p.Message,
+			}
+
+			if len(p.RelatedObjects) == 0 {
+				if err := writer.Write(append(policyColumns, "", "", "", "", "", "")); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			for _, related := range p.RelatedObjects {
+				row := append(append([]string{}, policyColumns...),
+					related.Object.APIVersion, related.Object.Kind, related.Object.Metadata.Namespace,
+					related.Object.Metadata.Name, string(related.ComplianceState), string(related.Reason))
+
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// relatedObjectString describes the related object on one line, for the
+// formats which show violations as text.
+func relatedObjectString(related v1alpha1.RelatedObject) string {
+	name := related.Object.Metadata.Name
+	if related.Object.Metadata.Namespace != "" {
+		name = related.Object.Metadata.Namespace + "/" + name
+	}
+
+	msg := fmt.Sprintf("%v %v %v", related.ComplianceState, related.Object.Kind, name)
+	if related.Reason != "" {
+		msg += ": " + string(related.Reason)
+	}
+
+	return msg
+}
+
+// policyName identifies the policy within a report, as "<kind> <namespace>/<name>".
+func policyName(p Policy) string {
+	if p.Metadata.Namespace == "" {
+		return p.Kind + " " + p.Metadata.Name
+	}
+
+	return p.Kind + " " + p.Metadata.Namespace + "/" + p.Metadata.Name
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func testReport() *Report {
+	noncompliant := policy("PolicyType", "default", "noncompliant", v1alpha1.SeverityHigh, v1alpha1.NonCompliant)
+	noncompliant.Message = "violations were found"
+	noncompliant.RelatedObjects = []v1alpha1.RelatedObject{{
+		Object: v1alpha1.ObjectRef{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			Metadata: v1alpha1.ObjectMetadata{Name: "a", Namespace: "default"},
+		},
+		ComplianceState: v1alpha1.NonCompliant,
+		Reason:          "missing",
+	}, {
+		Object: v1alpha1.ObjectRef{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			Metadata: v1alpha1.ObjectMetadata{Name: "b", Namespace: "default"},
+		},
+		ComplianceState: v1alpha1.Compliant,
+	}}
+
+	return New([]Policy{
+		noncompliant,
+		policy("PolicyType", "default", "compliant", v1alpha1.SeverityLow, v1alpha1.Compliant),
+		policy("PolicyType", "", "unknown", "", v1alpha1.UnknownCompliancy),
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, testReport(), FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error reading the CSV: %v", err)
+	}
+
+	if !reflect.DeepEqual(rows[0], csvHeader) {
+		t.Errorf("expected the header row: %v, got: %v", csvHeader, rows[0])
+	}
+
+	// One row for each related object of the NonCompliant policy, and one each
+	// for the other policies
+	type test struct {
+		name        string
+		row         []string
+		wantName    string
+		wantRelated string
+	}
+
+	tests := []test{
+		{name: "first related object", row: rows[1], wantName: "noncompliant", wantRelated: "a"},
+		{name: "second related object", row: rows[2], wantName: "noncompliant", wantRelated: "b"},
+		{name: "low policy", row: rows[3], wantName: "compliant", wantRelated: ""},
+		{name: "unspecified policy", row: rows[4], wantName: "unknown", wantRelated: ""},
+	}
+
+	if len(rows) != len(tests)+1 {
+		t.Fatalf("expected %v rows, got: %v", len(tests)+1, len(rows))
+	}
+
+	for _, tc := range tests {
+		if tc.row[4] != tc.wantName || tc.row[12] != tc.wantRelated {
+			t.Errorf("test '%v' expected: %v %v, got: %v", tc.name, tc.wantName, tc.wantRelated, tc.row)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, testReport(), FormatJUnit); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := junitTestSuites{}
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error reading the XML: %v", err)
+	}
+
+	if got.Tests != 3 || got.Failures != 2 || got.Skipped != 0 {
+		t.Errorf("expected 3 tests with 2 failures, got: %v tests with %v failures and %v skipped",
+			got.Tests, got.Failures, got.Skipped)
+	}
+
+	if len(got.Suites) != 3 || got.Suites[0].Name != "high/default" {
+		t.Fatalf("expected 3 suites starting with high/default, got: %+v", got.Suites)
+	}
+
+	failure := got.Suites[0].Cases[0].Failure
+	if failure == nil || failure.Message != "violations were found" ||
+		failure.Text != "NonCompliant ConfigMap default/a: missing" {
+		t.Errorf("expected a failure for the NonCompliant ConfigMap, got: %+v", failure)
+	}
+
+	defer func(orig bool) { v1alpha1.UnknownCompliancyMeansViolation = orig }(v1alpha1.UnknownCompliancyMeansViolation)
+	v1alpha1.UnknownCompliancyMeansViolation = false
+
+	buf.Reset()
+
+	if err := WriteJUnit(buf, testReport()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got = junitTestSuites{}
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error reading the XML: %v", err)
+	}
+
+	if got.Failures != 1 || got.Skipped != 1 {
+		t.Errorf("expected the unknown policy to be skipped, got: %v failures and %v skipped",
+			got.Failures, got.Skipped)
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := Write(buf, testReport(), FormatSARIF); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := sarifLog{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error reading the SARIF: %v", err)
+	}
+
+	if got.Version != sarifVersion || len(got.Runs) != 1 {
+		t.Fatalf("expected a single SARIF %v run, got: %+v", sarifVersion, got)
+	}
+
+	run := got.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("expected a rule for each policy, got: %v", len(run.Tool.Driver.Rules))
+	}
+
+	type test struct {
+		ruleID   string
+		level    string
+		location string
+	}
+
+	want := []test{
+		{
+			ruleID:   "policy.open-cluster-management.io/v1alpha1/PolicyType/default/noncompliant",
+			level:    "error",
+			location: "v1/ConfigMap/default/a",
+		}, {
+			ruleID:   "policy.open-cluster-management.io/v1alpha1/PolicyType/unknown",
+			level:    "warning",
+			location: "policy.open-cluster-management.io/v1alpha1/PolicyType/unknown",
+		},
+	}
+
+	results := make([]test, len(run.Results))
+	for i, result := range run.Results {
+		results[i] = test{
+			ruleID:   result.RuleID,
+			level:    result.Level,
+			location: result.Locations[0].LogicalLocations[0].FullyQualifiedName,
+		}
+
+		if rule := run.Tool.Driver.Rules[result.RuleIndex]; rule.ID != result.RuleID {
+			t.Errorf("expected the ruleIndex of %v to point at its rule, got: %v", result.RuleID, rule.ID)
+		}
+	}
+
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("expected results: %+v, got: %+v", want, results)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, testReport(), "yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
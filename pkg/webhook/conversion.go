@@ -0,0 +1,47 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+// SetupConversionWithManager registers the given policy type with the
+// manager's webhook server, so that it is served on the `/convert` path used by
+// the conversion webhook in the CRD. The policy only needs to be an empty
+// instance of one version of the type, for example `&v1alpha1.PolicyType{}`.
+// Every version of the type must already be registered in the manager's scheme,
+// and one of them must be a conversion.Hub while the others are
+// conversion.Convertible, otherwise an error is returned.
+func SetupConversionWithManager(mgr ctrl.Manager, policy runtime.Object) error {
+	convertible, err := conversion.IsConvertible(mgr.GetScheme(), policy)
+	if err != nil {
+		return err
+	}
+
+	if !convertible {
+		return fmt.Errorf("the versions of %T registered in the scheme can not be converted", policy)
+	}
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(policy).
+		Complete()
+}
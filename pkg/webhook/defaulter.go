@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// PolicyDefaulter is an admission.CustomDefaulter which sets the Severity and
+// RemediationAction of policies to their canonical lowercase forms.
+type PolicyDefaulter struct{}
+
+// blank assignment to verify that PolicyDefaulter implements CustomDefaulter
+var _ admission.CustomDefaulter = &PolicyDefaulter{}
+
+// SetupDefaulterWithManager registers the PolicyDefaulter for the given policy
+// type with the manager's webhook server. The policy only needs to be an empty
+// instance of the concrete type, for example `&MockPolicy{}`. The webhook will
+// be served on the standard path generated by controller-runtime, which can be
+// configured with a kubebuilder tag like this one:
+// `//+kubebuilder:webhook:path=/mutate-policy-open-cluster-management-io-v1alpha1-mockpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=policy.open-cluster-management.io,resources=mockpolicies,verbs=create;update,versions=v1alpha1,name=mmockpolicy.kb.io,admissionReviewVersions=v1`
+func SetupDefaulterWithManager(mgr ctrl.Manager, policy v1alpha1.PolicyTyper) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(policy).
+		WithDefaulter(&PolicyDefaulter{}).
+		Complete()
+}
+
+// Default implements admission.CustomDefaulter.
+func (d *PolicyDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	policy, ok := obj.(v1alpha1.PolicyTyper)
+	if !ok {
+		return fmt.Errorf("expected a PolicyTyper but got a %T", obj)
+	}
+
+	policy.PolicySpec().Default()
+
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestPolicyDefaulter(t *testing.T) {
+	type test struct {
+		name       string
+		spec       v1alpha1.PolicyTypeSpec
+		wantSev    string
+		wantAction string
+	}
+
+	tests := []test{
+		{
+			name:       "already canonical",
+			spec:       v1alpha1.PolicyTypeSpec{Severity: "low", RemediationAction: "inform"},
+			wantSev:    "low",
+			wantAction: "inform",
+		}, {
+			name:       "capitalized",
+			spec:       v1alpha1.PolicyTypeSpec{Severity: "Critical", RemediationAction: "Enforce"},
+			wantSev:    "critical",
+			wantAction: "enforce",
+		}, {
+			name:       "unset",
+			spec:       v1alpha1.PolicyTypeSpec{},
+			wantSev:    "",
+			wantAction: "",
+		}, {
+			name:       "invalid values are left for validation",
+			spec:       v1alpha1.PolicyTypeSpec{Severity: "Urgent", RemediationAction: "Fix"},
+			wantSev:    "Urgent",
+			wantAction: "Fix",
+		},
+	}
+
+	d := &PolicyDefaulter{}
+
+	for _, tc := range tests {
+		policy := &v1alpha1.PolicyType{Spec: tc.spec}
+
+		if err := d.Default(context.TODO(), policy); err != nil {
+			t.Errorf("test '%v' unexpected error: %v", tc.name, err)
+		}
+		if policy.Spec.Severity != tc.wantSev {
+			t.Errorf("test '%v' expected severity: %v, got: %v", tc.name, tc.wantSev, policy.Spec.Severity)
+		}
+		if policy.Spec.RemediationAction != tc.wantAction {
+			t.Errorf("test '%v' expected remediationAction: %v, got: %v",
+				tc.name, tc.wantAction, policy.Spec.RemediationAction)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains admission webhooks which can be registered for any
+// policy type in the policy framework, to check the fields which are common to
+// all policy types, and to normalize them, before the policy is stored.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+// PolicyValidator is an admission.CustomValidator which rejects policies with
+// an invalid PolicyTypeSpec, for example with a malformed namespace pattern, or
+// with a RemediationAction that the policy type does not support (see
+// v1alpha1.RemediationActionSupporter).
+type PolicyValidator struct{}
+
+// blank assignment to verify that PolicyValidator implements CustomValidator
+var _ admission.CustomValidator = &PolicyValidator{}
+
+// SetupValidatorWithManager registers the PolicyValidator for the given policy
+// type with the manager's webhook server. The policy only needs to be an empty
+// instance of the concrete type, for example `&MockPolicy{}`. The webhook will
+// be served on the standard path generated by controller-runtime, which can be
+// configured with a kubebuilder tag like this one:
+// `//+kubebuilder:webhook:path=/validate-policy-open-cluster-management-io-v1alpha1-mockpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=policy.open-cluster-management.io,resources=mockpolicies,verbs=create;update,versions=v1alpha1,name=vmockpolicy.kb.io,admissionReviewVersions=v1`
+func SetupValidatorWithManager(mgr ctrl.Manager, policy v1alpha1.PolicyTyper) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(policy).
+		WithValidator(&PolicyValidator{}).
+		Complete()
+}
+
+// ValidateCreate implements admission.CustomValidator.
+func (v *PolicyValidator) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	return validate(obj)
+}
+
+// ValidateUpdate implements admission.CustomValidator.
+func (v *PolicyValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) error {
+	return validate(newObj)
+}
+
+// ValidateDelete implements admission.CustomValidator. Deletions are always
+// allowed, so that invalid policies can be cleaned up.
+func (v *PolicyValidator) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	return nil
+}
+
+// validate returns an Invalid error listing every problem in the policy's
+// PolicyTypeSpec, or nil if there are no problems.
+func validate(obj runtime.Object) error {
+	policy, ok := obj.(v1alpha1.PolicyTyper)
+	if !ok {
+		return fmt.Errorf("expected a PolicyTyper but got a %T", obj)
+	}
+
+	errs := v1alpha1.ValidatePolicy(policy)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		policy.GetObjectKind().GroupVersionKind().GroupKind(), policy.GetName(), errs)
+}
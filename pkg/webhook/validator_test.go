@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+)
+
+func TestPolicyValidator(t *testing.T) {
+	type test struct {
+		name    string
+		spec    v1alpha1.PolicyTypeSpec
+		wantErr bool
+	}
+
+	validSelector := v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}}
+
+	tests := []test{
+		{
+			name: "valid spec",
+			spec: v1alpha1.PolicyTypeSpec{
+				Severity:          "High",
+				RemediationAction: "inform",
+				NamespaceSelector: validSelector,
+				LabelSelector:     map[string]v1alpha1.NonEmptyString{"app": "foo"},
+			},
+		}, {
+			name: "bad glob",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: v1alpha1.NamespaceSelector{
+				Include: []v1alpha1.NonEmptyString{"kube-[a"},
+			}},
+			wantErr: true,
+		}, {
+			name: "bad regex",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: v1alpha1.NamespaceSelector{
+				Include: []v1alpha1.NonEmptyString{"*"},
+				Exclude: []v1alpha1.NonEmptyString{"regex:(kube"},
+			}},
+			wantErr: true,
+		}, {
+			name: "empty matchExpressions values",
+			spec: v1alpha1.PolicyTypeSpec{NamespaceSelector: v1alpha1.NamespaceSelector{
+				Include: []v1alpha1.NonEmptyString{"*"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      "env",
+					Operator: metav1.LabelSelectorOpIn,
+				}},
+			}},
+			wantErr: true,
+		}, {
+			name: "empty labelSelector value",
+			spec: v1alpha1.PolicyTypeSpec{
+				NamespaceSelector: validSelector,
+				LabelSelector:     map[string]v1alpha1.NonEmptyString{"app": ""},
+			},
+			wantErr: true,
+		}, {
+			name:    "bad severity",
+			spec:    v1alpha1.PolicyTypeSpec{Severity: "urgent", NamespaceSelector: validSelector},
+			wantErr: true,
+		}, {
+			name:    "bad remediationAction",
+			spec:    v1alpha1.PolicyTypeSpec{RemediationAction: "fix", NamespaceSelector: validSelector},
+			wantErr: true,
+		},
+	}
+
+	v := &PolicyValidator{}
+
+	for _, tc := range tests {
+		policy := &v1alpha1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       tc.spec,
+		}
+
+		err := v.ValidateCreate(context.TODO(), policy)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+		if err != nil && !apierrors.IsInvalid(err) {
+			t.Errorf("test '%v' expected an Invalid error, got: %v", tc.name, err)
+		}
+
+		err = v.ValidateUpdate(context.TODO(), &v1alpha1.PolicyType{}, policy)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("test '%v' expected update error: %v, got: %v", tc.name, tc.wantErr, err)
+		}
+
+		if err := v.ValidateDelete(context.TODO(), policy); err != nil {
+			t.Errorf("test '%v' expected delete to be allowed, got: %v", tc.name, err)
+		}
+	}
+}
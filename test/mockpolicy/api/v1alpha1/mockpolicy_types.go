@@ -48,11 +48,11 @@ type MockPolicy struct {
 	Status MockPolicyStatus `json:"status,omitempty"`
 }
 
-func (p MockPolicy) PolicySpec() *framework.PolicyTypeSpec {
+func (p *MockPolicy) PolicySpec() *framework.PolicyTypeSpec {
 	return &p.Spec.PolicyTypeSpec
 }
 
-func (p MockPolicy) PolicyStatus() *framework.PolicyTypeStatus {
+func (p *MockPolicy) PolicyStatus() *framework.PolicyTypeStatus {
 	return &p.Status.PolicyTypeStatus
 }
 
@@ -0,0 +1,36 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/JustinKuli/policy-framework/pkg/webhook"
+)
+
+//+kubebuilder:webhook:path=/mutate-policy-open-cluster-management-io-v1alpha1-mockpolicy,mutating=true,failurePolicy=fail,sideEffects=None,groups=policy.open-cluster-management.io,resources=mockpolicies,verbs=create;update,versions=v1alpha1,name=mmockpolicy.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-policy-open-cluster-management-io-v1alpha1-mockpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=policy.open-cluster-management.io,resources=mockpolicies,verbs=create;update,versions=v1alpha1,name=vmockpolicy.kb.io,admissionReviewVersions=v1
+
+// SetupWebhookWithManager registers the framework's defaulting and validating
+// webhooks for MockPolicy with the manager.
+func (p *MockPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	if err := webhook.SetupDefaulterWithManager(mgr, p); err != nil {
+		return err
+	}
+
+	return webhook.SetupValidatorWithManager(mgr, p)
+}
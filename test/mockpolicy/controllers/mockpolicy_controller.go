@@ -20,14 +20,13 @@ import (
 	"context"
 	"strings"
 
-	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/pkg/reconciler"
 	policyv1alpha1 "github.com/JustinKuli/policy-framework/test/mockpolicy/api/v1alpha1"
 )
 
@@ -44,53 +43,40 @@ type MockPolicyReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
-func (r *MockPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log := ctrllog.FromContext(ctx)
-
-	policy := &policyv1alpha1.MockPolicy{}
-	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
-		if errors.IsNotFound(err) {
-			// Request object not found, probably deleted
-			return ctrl.Result{}, nil
-		}
-		log.Error(err, "Failed to get MockPolicy")
-		return ctrl.Result{}, err
-	}
+// Evaluate determines the compliance of a MockPolicy, based on its Foo field.
+// The rest of the reconcile is handled by the framework's PolicyReconciler.
+func (r *MockPolicyReconciler) Evaluate(ctx context.Context, p v1alpha1.PolicyTyper) (
+	v1alpha1.ComplianceState, []v1alpha1.RelatedObject, string, string, error,
+) {
+	policy := p.(*policyv1alpha1.MockPolicy)
 
 	switch policy.Spec.Foo {
 	case "nstest":
 		selectedNamespaces, err := policy.Spec.NamespaceSelector.GetNamespaces(ctx, r.Client)
 		if err != nil {
-			log.Error(err, "Failed to GetNamespaces using NamespaceSelector",
-				"selector", policy.Spec.NamespaceSelector)
-			return ctrl.Result{}, err
+			return "", nil, "", "", err
 		}
 		policy.Status.Debug = strings.Join(selectedNamespaces, ",")
 	case "compliant":
-		policy.Status.ComplianceState = v1alpha1.Compliant
+		return v1alpha1.Compliant, nil, "", "because test", nil
 	case "noncompliant":
-		policy.Status.ComplianceState = v1alpha1.NonCompliant
+		return v1alpha1.NonCompliant, nil, "", "because test", nil
 	}
 
-	err := r.Status().Update(ctx, policy)
-	if err != nil {
-		log.Error(err, "Failed to update status")
-		return ctrl.Result{}, err
-	}
-
-	v1alpha1.RecordComplianceEvent(r.Recorder, policy, "because test")
-
-	return ctrl.Result{}, nil
+	return policy.Status.ComplianceState, nil, "", "because test", nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *MockPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&policyv1alpha1.MockPolicy{}).
-		Complete(r)
+	return (&reconciler.PolicyReconciler{
+		Client:   r.Client,
+		Recorder: r.Recorder,
+		NewPolicy: func() v1alpha1.PolicyTyper {
+			return &policyv1alpha1.MockPolicy{}
+		},
+		NewPolicyList: func() client.ObjectList {
+			return &policyv1alpha1.MockPolicyList{}
+		},
+		Evaluate: r.Evaluate,
+	}).SetupWithManager(mgr)
 }
@@ -0,0 +1,78 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+)
+
+var _ = Describe("PolicyType conversion", Ordered, func() {
+	key := types.NamespacedName{Namespace: "default", Name: "conversion"}
+
+	AfterAll(func() {
+		policy := &v1alpha1.PolicyType{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+		Expect(k8sClient.Delete(ctx, policy)).Should(Succeed())
+	})
+
+	It("Should store a v1alpha1 policy as v1beta1", func() {
+		policy := &v1alpha1.PolicyType{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Spec: v1alpha1.PolicyTypeSpec{
+				Severity:          "High",
+				RemediationAction: "Inform",
+				NamespaceSelector: v1alpha1.NamespaceSelector{Include: []v1alpha1.NonEmptyString{"*"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, policy)).Should(Succeed())
+
+		policy.Status.ComplianceState = v1alpha1.NonCompliant
+		policy.Status.RelatedObjects = []v1alpha1.RelatedObject{{
+			Object: v1alpha1.ObjectRef{
+				TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+				Metadata: v1alpha1.ObjectMetadata{Name: "foo", Namespace: "default"},
+			},
+			ComplianceState: v1alpha1.NonCompliant,
+			Reason:          "missing",
+		}}
+		Expect(k8sClient.Status().Update(ctx, policy)).Should(Succeed())
+
+		hub := &v1beta1.PolicyType{}
+		Expect(k8sClient.Get(ctx, key, hub)).Should(Succeed())
+		Expect(hub.Spec.Severity).Should(Equal(v1beta1.SeverityHigh))
+		Expect(hub.Spec.RemediationAction).Should(Equal(v1beta1.RemediationInform))
+		Expect(hub.Status.ComplianceState).Should(Equal(v1beta1.NonCompliant))
+		Expect(hub.Status.RelatedObjects).Should(HaveLen(1))
+		Expect(hub.Status.RelatedObjects[0].ComplianceState).Should(Equal(v1beta1.NonCompliant))
+	})
+
+	It("Should read the policy back as v1alpha1", func() {
+		policy := &v1alpha1.PolicyType{}
+		Expect(k8sClient.Get(ctx, key, policy)).Should(Succeed())
+		Expect(policy.Spec.GetSeverity()).Should(Equal(v1alpha1.SeverityHigh))
+		Expect(policy.Spec.GetRemediationAction()).Should(Equal(v1alpha1.RemediationInform))
+		Expect(policy.Spec.NamespaceSelector.Include).Should(Equal([]v1alpha1.NonEmptyString{"*"}))
+		Expect(policy.Status.ComplianceState).Should(Equal(v1alpha1.NonCompliant))
+		Expect(policy.Status.RelatedObjects).Should(HaveLen(1))
+		Expect(policy.Status.RelatedObjects[0].ComplianceState).Should(Equal(v1alpha1.NonCompliant))
+	})
+})
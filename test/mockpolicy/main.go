@@ -21,12 +21,16 @@ import (
 	"os"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/JustinKuli/policy-framework/api/v1alpha1"
+	"github.com/JustinKuli/policy-framework/api/v1beta1"
+	"github.com/JustinKuli/policy-framework/pkg/webhook"
 	policyv1alpha1 "github.com/JustinKuli/policy-framework/test/mockpolicy/api/v1alpha1"
 	"github.com/JustinKuli/policy-framework/test/mockpolicy/controllers"
 	//+kubebuilder:scaffold:imports
@@ -42,6 +46,16 @@ func init() {
 
 	utilruntime.Must(policyv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
+
+	// Both versions of the framework's PolicyType are needed to serve /convert
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(v1beta1.AddToScheme(scheme))
+
+	// The mock policies use other MockPolicies as their parents
+	v1alpha1.RegisterParentPolicyKind(schema.GroupKind{
+		Group: policyv1alpha1.GroupVersion.Group,
+		Kind:  "MockPolicy",
+	})
 }
 
 func main() {
@@ -82,6 +96,16 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "MockPolicy")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&policyv1alpha1.MockPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "MockPolicy")
+			os.Exit(1)
+		}
+		if err = webhook.SetupConversionWithManager(mgr, &v1alpha1.PolicyType{}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PolicyType")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {